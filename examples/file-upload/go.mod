@@ -4,4 +4,9 @@ go 1.21
 
 require github.com/alejandrombjs/go-bastion-lib v0.0.0
 
-replace github.com/alejandrombjs/go-bastion-lib => ../../
\ No newline at end of file
+require (
+	github.com/golang-jwt/jwt/v5 v5.2.0 // indirect
+	golang.org/x/crypto v0.17.0 // indirect
+)
+
+replace github.com/alejandrombjs/go-bastion-lib => ../../
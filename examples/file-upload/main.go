@@ -86,24 +86,8 @@ func main() {
 	})
 
 	// --- Serve Uploaded Files Endpoint ---
-	r.GET("/files/:name", func(ctx *router.Context) {
-		filename := ctx.Param("name")
-		if strings.Contains(filename, "..") { // Prevent path traversal
-			response.Error(ctx, http.StatusBadRequest, "invalid_filename", "Filename contains invalid characters")
-			return
-		}
-
-		filePath := filepath.Join(uploadDir, filename)
-
-		// Check if file exists
-		if _, err := os.Stat(filePath); os.IsNotExist(err) {
-			response.Error(ctx, http.StatusNotFound, "file_not_found", "File not found")
-			return
-		}
-
-		// Serve the file
-		http.ServeFile(ctx.ResponseWriter(), ctx.Request().Request, filePath)
-	})
+	// Static cleans and validates the path, so "../" cannot escape uploadDir.
+	r.Static("/files", os.DirFS(uploadDir), router.StaticOptions{})
 
 	log.Printf("File Upload example server starting on :%d", cfg.Port)
 	if err := app.Run(); err != nil {
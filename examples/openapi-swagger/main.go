@@ -1,14 +1,24 @@
 package main
 
 import (
+	"fmt"
 	"log"
 	"net/http"
 
 	"github.com/alejandrombjs/go-bastion-lib/pkg/bastion"
 	"github.com/alejandrombjs/go-bastion-lib/pkg/middleware"
+	"github.com/alejandrombjs/go-bastion-lib/pkg/openapi"
 	"github.com/alejandrombjs/go-bastion-lib/pkg/router"
 )
 
+type helloRequest struct {
+	Name string `query:"name" validate:"omitempty,max=64"`
+}
+
+type helloResponse struct {
+	Message string `json:"message"`
+}
+
 func main() {
 	cfg := bastion.DefaultConfig()
 	cfg.Port = 8087 // Use a different port for this example
@@ -22,32 +32,28 @@ func main() {
 		middleware.DefaultRecovery(),
 	)
 
-	// --- Serve OpenAPI Specification ---
-	// This endpoint serves the openapi.yaml file directly.
-	r.GET("/openapi.yaml", func(ctx *router.Context) {
-		http.ServeFile(ctx.ResponseWriter(), ctx.Request().Request, "./openapi.yaml")
-	})
-
-	// --- Serve Swagger UI ---
-	// This endpoint redirects to a public Swagger UI instance,
-	// configured to load our local openapi.yaml.
-	// In a production setup, you would typically serve Swagger UI static files
-	// directly from your application or a CDN.
-	r.GET("/docs", func(ctx *router.Context) {
-		swaggerUIURL := fmt.Sprintf("https://petstore.swagger.io/?url=http://localhost:%d/openapi.yaml", cfg.Port)
-		http.Redirect(ctx.ResponseWriter(), ctx.Request().Request, swaggerUIURL, http.StatusMovedPermanently)
-	})
-
-	// --- Example API Endpoint (documented in openapi.yaml) ---
-	r.GET("/api/hello", func(ctx *router.Context) {
-		name := ctx.Query("name")
-		if name == "" {
-			name = "World"
+	// --- Example API Endpoint ---
+	// Typed handlers contribute their parameters and response schema to the
+	// generated document.
+	r.HandleTyped(http.MethodGet, "/api/hello", router.Typed(func(ctx *router.Context, in helloRequest) (helloResponse, error) {
+		if in.Name == "" {
+			in.Name = "World"
 		}
-		ctx.JSON(http.StatusOK, map[string]string{"message": fmt.Sprintf("Hello, %s!", name)})
+		return helloResponse{Message: fmt.Sprintf("Hello, %s!", in.Name)}, nil
+	})).
+		Meta("summary", "Greet someone").
+		Meta("tags", []string{"Greetings"})
+
+	// --- OpenAPI document: GET /openapi.json and GET /openapi.yaml ---
+	openapi.Register(r, openapi.Config{Title: "Hello API", Version: "1.0.0"})
+
+	// --- API reference UI: GET /docs, served from the binary ---
+	openapi.Docs(r, openapi.DocsConfig{
+		Title:    "Hello API",
+		Disabled: cfg.Env == "production",
 	})
 
-	log.Printf("OpenAPI-Swagger example server starting on :%d", cfg.Port)
+	log.Printf("OpenAPI example server starting on :%d", cfg.Port)
 	if err := app.Run(); err != nil {
 		log.Fatalf("Server failed: %v", err)
 	}
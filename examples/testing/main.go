@@ -15,8 +15,8 @@ import (
 // User represents a simple user model
 type User struct {
 	ID   int    `json:"id"`
-	Name string `json:"name"`
-	Age  int    `json:"age"`
+	Name string `json:"name" validate:"required,max=100"`
+	Age  int    `json:"age" validate:"min=1,max=150"`
 }
 
 // In-memory store for users (for simplicity)
@@ -68,13 +68,8 @@ func ListUsersHandler(ctx *router.Context) {
 // CreateUserHandler creates a new user
 func CreateUserHandler(ctx *router.Context) {
 	var newUser User
-	if err := ctx.BindJSON(&newUser); err != nil {
-		response.Error(ctx, http.StatusBadRequest, "invalid_request", "Invalid JSON body")
-		return
-	}
-
-	if newUser.Name == "" || newUser.Age <= 0 {
-		response.Error(ctx, http.StatusBadRequest, "validation_error", "Name and age must be provided and valid")
+	if err := ctx.Bind(&newUser); err != nil {
+		response.ValidationError(ctx, err)
 		return
 	}
 
@@ -72,7 +72,7 @@ func TestCreateUserHandler_Success(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "Charlie", resp.Name)
 	assert.Equal(t, 25, resp.Age)
-	assert.Equal(t, initialNextUserID, resp.ID) // Check if ID is correctly assigned
+	assert.Equal(t, initialNextUserID, resp.ID)  // Check if ID is correctly assigned
 	assert.Contains(t, users, initialNextUserID) // Check if user is added to map
 }
 
@@ -85,11 +85,24 @@ func TestCreateUserHandler_InvalidInput(t *testing.T) {
 
 	CreateUserHandler(ctx)
 
-	assert.Equal(t, http.StatusBadRequest, w.Code)
-	var resp map[string]map[string]string
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	var resp struct {
+		Error struct {
+			Code    string `json:"code"`
+			Details []struct {
+				Field string `json:"field"`
+				Code  string `json:"code"`
+			} `json:"details"`
+		} `json:"error"`
+	}
 	err := json.Unmarshal(w.Body.Bytes(), &resp)
 	assert.NoError(t, err)
-	assert.Equal(t, "validation_error", resp["error"]["code"])
+	assert.Equal(t, "validation_failed", resp.Error.Code)
+	assert.Len(t, resp.Error.Details, 2)
+	assert.Equal(t, "name", resp.Error.Details[0].Field)
+	assert.Equal(t, "required", resp.Error.Details[0].Code)
+	assert.Equal(t, "age", resp.Error.Details[1].Field)
+	assert.Equal(t, "min", resp.Error.Details[1].Code)
 }
 
 func TestGetUserHandler_Success(t *testing.T) {
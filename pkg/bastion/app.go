@@ -4,11 +4,14 @@ package bastion
 import (
 	"context"
 	"fmt"
+	"html/template"
+	"io"
 	"log" // Added for logging fatal errors
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"text/tabwriter"
 	"time"
 
 	"github.com/alejandrombjs/go-bastion-lib/pkg/router"
@@ -33,14 +36,18 @@ func NewApp(cfg Config) *App {
 		shutdown: make(chan os.Signal, 1),
 	}
 
-	// Initialize the default templating engine
+	// Errors returned by handlers only show their cause in development.
 	isDevelopment := cfg.Env == "development"
+	r.SetErrorHandler(router.NewErrorHandler(router.ErrorHandlerConfig{Development: isDevelopment}))
+
+	// Initialize the default templating engine
 	err := templating.InitDefault(templating.Options{
 		Root:         cfg.TemplateRoot, // Use TemplateRoot from config
 		Extensions:   []string{".gb.html", ".html"},
 		CacheEnabled: !isDevelopment,
 		Debug:        isDevelopment,
-		Funcs:        nil,
+		// {{ url "users.show" "id" .ID }} resolves named routes.
+		Funcs: template.FuncMap{"url": r.URL},
 	})
 	if err != nil {
 		log.Fatalf("failed to initialize templating engine: %v", err)
@@ -64,6 +71,21 @@ func (a *App) Router() *router.Router {
 	return a.router
 }
 
+// Routes lists the routes registered on the application router.
+func (a *App) Routes() []router.RouteInfo {
+	return a.router.Routes()
+}
+
+// PrintRoutes writes a table of the registered routes to w.
+func (a *App) PrintRoutes(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "METHOD\tPATH\tNAME\tMIDDLEWARE")
+	for _, rt := range a.Routes() {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\n", rt.Method, rt.Host+rt.Path, rt.Name, rt.Middleware)
+	}
+	tw.Flush()
+}
+
 // Use registers global middleware that will be applied to all routes.
 func (a *App) Use(middleware ...router.Middleware) {
 	a.router.Use(middleware...)
@@ -74,6 +96,9 @@ func (a *App) Use(middleware ...router.Middleware) {
 func (a *App) Run() error {
 	fmt.Printf("Starting server on :%d\n", a.config.Port)
 	fmt.Printf("Environment: %s\n", a.config.Env)
+	if a.config.Env == "development" {
+		a.PrintRoutes(os.Stdout)
+	}
 
 	// Start server in a goroutine so we can handle shutdown
 	errChan := make(chan error, 1)
@@ -105,6 +130,10 @@ func (a *App) Shutdown(ctx context.Context) error {
 func (a *App) RunWithGracefulShutdown() error {
 	errChan := make(chan error, 1)
 
+	if a.config.Env == "development" {
+		a.PrintRoutes(os.Stdout)
+	}
+
 	// Start server
 	go func() {
 		fmt.Printf("Starting server on :%d\n", a.config.Port)
@@ -0,0 +1,78 @@
+package openapi
+
+import (
+	"embed"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"strings"
+
+	"github.com/alejandrombjs/go-bastion-lib/pkg/router"
+)
+
+//go:embed docs
+var docsFS embed.FS
+
+var docsPage = template.Must(template.ParseFS(docsFS, "docs/index.html"))
+
+// DocsConfig configures the API reference UI served by Docs.
+type DocsConfig struct {
+	// Path is where the UI is served. Defaults to "/docs".
+	Path string
+	// Title is the page title. Defaults to "API Reference".
+	Title string
+	// SpecURL is the OpenAPI document the UI renders. It must be served as
+	// JSON. Defaults to "/openapi.json" below the prefix of the router
+	// passed to Docs, where Register serves the document of the same
+	// router with the default Config.Path.
+	SpecURL string
+	// Disabled makes Docs register nothing, e.g. in production.
+	Disabled bool
+}
+
+// Docs serves an interactive API reference at cfg.Path:
+//
+//	openapi.Register(r, openapi.Config{Title: "Shop API", Version: "1.4.0"})
+//	openapi.Docs(r, openapi.DocsConfig{Title: "Shop API", Disabled: cfg.Env == "production"})
+//
+// The page, its script and its stylesheet are embedded in the binary and
+// load nothing from other origins, so the UI works offline and under the
+// default Content-Security-Policy of "default-src 'self'". Assets are
+// served below cfg.Path+"/assets/". Neither route is documented.
+func Docs(r *router.Router, cfg DocsConfig) {
+	if cfg.Disabled {
+		return
+	}
+	if cfg.Path == "" {
+		cfg.Path = "/docs"
+	}
+	if cfg.Title == "" {
+		cfg.Title = "API Reference"
+	}
+
+	assets, err := fs.Sub(docsFS, "docs/assets")
+	if err != nil {
+		panic("openapi: " + err.Error())
+	}
+	path := strings.TrimSuffix(cfg.Path, "/")
+
+	r.GET(cfg.Path, router.E(func(ctx *router.Context) error {
+		// Asset URLs follow the request path and the default document
+		// URL the route's prefix, so the UI also works when r is a group.
+		base := strings.TrimSuffix(ctx.Request().URL.Path, "/")
+		specURL := cfg.SpecURL
+		if specURL == "" {
+			prefix := strings.TrimSuffix(strings.TrimSuffix(ctx.RoutePattern(), "/"), path)
+			specURL = prefix + "/openapi.json"
+		}
+		ctx.ResponseWriter().Header().Set("Content-Type", "text/html; charset=utf-8")
+		ctx.ResponseWriter().Header().Set("Cache-Control", "no-cache")
+		ctx.Status(http.StatusOK)
+		return docsPage.Execute(ctx.ResponseWriter(), map[string]string{
+			"Title":   cfg.Title,
+			"SpecURL": specURL,
+			"Assets":  base + "/assets",
+		})
+	})).Meta("hidden", true)
+	r.Static(path+"/assets", assets, router.StaticOptions{}).Meta("hidden", true)
+}
@@ -0,0 +1,302 @@
+// API reference for an OpenAPI 3.x document. Everything is built with DOM
+// APIs and textContent: the document is data, never markup.
+(function () {
+  "use strict";
+
+  var METHODS = ["get", "post", "put", "patch", "delete", "head", "options", "trace"];
+  var CONSTRAINTS = [
+    ["format", "format"], ["minimum", "≥"], ["maximum", "≤"],
+    ["minLength", "min length"], ["maxLength", "max length"],
+    ["minItems", "min items"], ["maxItems", "max items"], ["pattern", "pattern"]
+  ];
+
+  var main = document.getElementById("docs");
+  var nav = document.getElementById("nav");
+  var filter = document.getElementById("filter");
+  var spec = {};
+
+  function el(tag, attrs) {
+    var node = document.createElement(tag);
+    Object.keys(attrs || {}).forEach(function (key) {
+      if (attrs[key] !== undefined && attrs[key] !== null) {
+        node.setAttribute(key, attrs[key]);
+      }
+    });
+    for (var i = 2; i < arguments.length; i++) {
+      append(node, arguments[i]);
+    }
+    return node;
+  }
+
+  function append(node, child) {
+    if (child === undefined || child === null || child === false) {
+      return;
+    }
+    if (Array.isArray(child)) {
+      child.forEach(function (c) { append(node, c); });
+      return;
+    }
+    node.appendChild(typeof child === "object" ? child : document.createTextNode(String(child)));
+  }
+
+  function text(value) {
+    return value === undefined ? "" : String(value);
+  }
+
+  // resolve follows a local "#/..." reference.
+  function resolve(ref) {
+    if (ref.indexOf("#/") !== 0) {
+      return null;
+    }
+    return ref.slice(2).split("/").reduce(function (node, part) {
+      part = decodeURIComponent(part).replace(/~1/g, "/").replace(/~0/g, "~");
+      return node && node[part];
+    }, spec);
+  }
+
+  function deref(obj) {
+    var seen = 0;
+    while (obj && obj.$ref && seen++ < 32) {
+      obj = resolve(obj.$ref) || {};
+    }
+    return obj || {};
+  }
+
+  function refName(ref) {
+    return ref.slice(ref.lastIndexOf("/") + 1);
+  }
+
+  function typeLabel(schema) {
+    if (schema.$ref) {
+      return el("span", { "class": "ref" }, refName(schema.$ref));
+    }
+    var type = Array.isArray(schema.type) ? schema.type.join(" | ") : schema.type;
+    if (type === "array" && schema.items) {
+      return [el("span", { "class": "type" }, "array of "), typeLabel(schema.items)];
+    }
+    if (!type && (schema.oneOf || schema.anyOf)) {
+      return el("span", { "class": "type" }, (schema.oneOf || schema.anyOf).map(function (s) {
+        return s.$ref ? refName(s.$ref) : text(s.type);
+      }).join(" | "));
+    }
+    return el("span", { "class": "type" }, type || "any");
+  }
+
+  function constraints(schema) {
+    var parts = [];
+    CONSTRAINTS.forEach(function (c) {
+      if (schema[c[0]] !== undefined) {
+        parts.push(c[1] + " " + schema[c[0]]);
+      }
+    });
+    if (schema.enum) {
+      parts.push("one of " + schema.enum.map(function (v) { return JSON.stringify(v); }).join(", "));
+    }
+    return parts.length ? el("span", { "class": "constraints" }, " (" + parts.join(", ") + ")") : null;
+  }
+
+  // schemaTree renders a schema as nested property lists. Referenced
+  // schemas are expanded once per branch, so recursive types terminate.
+  function schemaTree(schema, seen) {
+    seen = seen || [];
+    var ref = schema.$ref;
+    if (ref && seen.indexOf(ref) >= 0) {
+      return null;
+    }
+    var target = deref(schema);
+    if (target.type === "array" && target.items) {
+      return schemaTree(target.items, ref ? seen.concat(ref) : seen);
+    }
+    if (target.additionalProperties && typeof target.additionalProperties === "object") {
+      return el("ul", null, el("li", null, el("code", null, "{key}"), ": ",
+        typeLabel(target.additionalProperties), schemaTree(target.additionalProperties, seen)));
+    }
+    var props = target.properties;
+    if (!props || !Object.keys(props).length) {
+      return null;
+    }
+    var required = target.required || [];
+    var next = ref ? seen.concat(ref) : seen;
+    return el("ul", null, Object.keys(props).map(function (name) {
+      var prop = props[name];
+      return el("li", null,
+        el("code", null, name), ": ", typeLabel(prop),
+        required.indexOf(name) >= 0 ? el("span", { "class": "required" }, " required") : null,
+        constraints(prop.$ref ? {} : prop),
+        prop.description ? el("span", { "class": "constraints" }, " — " + prop.description) : null,
+        schemaTree(prop, next));
+    }));
+  }
+
+  function schemaBlock(schema) {
+    return el("div", { "class": "schema" }, typeLabel(schema), constraints(schema.$ref ? {} : schema), schemaTree(schema));
+  }
+
+  function contentBlocks(content) {
+    return Object.keys(content || {}).map(function (type) {
+      var media = content[type] || {};
+      return [el("div", { "class": "constraints" }, type), media.schema ? schemaBlock(media.schema) : null];
+    });
+  }
+
+  function parameters(params) {
+    if (!params.length) {
+      return null;
+    }
+    return [
+      el("h4", null, "Parameters"),
+      el("table", null,
+        el("thead", null, el("tr", null, el("th", null, "Name"), el("th", null, "In"), el("th", null, "Type"), el("th", null, "Description"))),
+        el("tbody", null, params.map(function (p) {
+          p = deref(p);
+          var schema = p.schema || {};
+          return el("tr", null,
+            el("td", null, el("code", null, p.name), p.required ? el("span", { "class": "required" }, " required") : null),
+            el("td", null, text(p.in)),
+            el("td", null, typeLabel(schema), constraints(schema)),
+            el("td", null, text(p.description)));
+        })))
+    ];
+  }
+
+  function responses(resps) {
+    return [
+      el("h4", null, "Responses"),
+      el("table", null, el("tbody", null, Object.keys(resps || {}).map(function (code) {
+        var r = deref(resps[code]);
+        return el("tr", null,
+          el("td", { "class": "response-code" }, code),
+          el("td", null, text(r.description), contentBlocks(r.content)));
+      })))
+    ];
+  }
+
+  function anchor(method, path) {
+    return "op-" + method + "-" + path.replace(/[^A-Za-z0-9]+/g, "-");
+  }
+
+  function operation(method, path, op, shared) {
+    var params = (shared || []).concat(op.parameters || []);
+    var secured = (op.security || spec.security || []).some(function (req) { return Object.keys(req).length > 0; });
+    var body = op.requestBody ? deref(op.requestBody) : null;
+    var details = el("details", {
+      "class": "op" + (op.deprecated ? " deprecated" : ""),
+      id: anchor(method, path),
+      "data-search": [method, path, op.summary, op.operationId, (op.tags || []).join(" ")].join(" ").toLowerCase()
+    },
+      el("summary", null,
+        el("span", { "class": "method " + method }, method),
+        el("span", { "class": "path" }, path),
+        el("span", { "class": "summary" }, text(op.summary)),
+        secured ? el("span", { "class": "badge", title: "Requires authentication" }, "auth") : null,
+        op.deprecated ? el("span", { "class": "badge" }, "deprecated") : null),
+      el("div", { "class": "body" },
+        op.description ? el("p", null, op.description) : null,
+        op.operationId ? el("p", { "class": "constraints" }, "Operation ID: ", el("code", null, op.operationId)) : null,
+        parameters(params),
+        body ? [el("h4", null, "Request body", body.required ? el("span", { "class": "required" }, " required") : null),
+          contentBlocks(body.content)] : null,
+        responses(op.responses)));
+    return details;
+  }
+
+  function render() {
+    var info = spec.info || {};
+    var groups = {};
+    var order = [];
+    Object.keys(spec.paths || {}).sort().forEach(function (path) {
+      var item = spec.paths[path] || {};
+      METHODS.forEach(function (method) {
+        var op = item[method];
+        if (!op) {
+          return;
+        }
+        var tag = (op.tags && op.tags[0]) || "default";
+        if (!groups[tag]) {
+          groups[tag] = [];
+          order.push(tag);
+        }
+        groups[tag].push(operation(method, path, op, item.parameters));
+      });
+    });
+    (spec.tags || []).slice().reverse().forEach(function (t) {
+      var i = order.indexOf(t.name);
+      if (i > 0) {
+        order.splice(i, 1);
+        order.unshift(t.name);
+      }
+    });
+
+    main.textContent = "";
+    nav.textContent = "";
+    append(main, el("section", { "class": "info" },
+      el("h2", null, text(info.title), " ", el("span", { "class": "version" }, info.version ? "v" + info.version : "")),
+      info.description ? el("p", null, info.description) : null,
+      spec.servers && spec.servers.length ? el("p", { "class": "servers" }, "Servers: ",
+        spec.servers.map(function (s) { return el("code", null, s.url); })) : null));
+
+    order.forEach(function (tag) {
+      var ops = groups[tag];
+      append(main, el("section", { "class": "group" }, el("h2", { "class": "tag" }, tag), ops));
+      append(nav, el("div", { "class": "group" }, el("h3", null, tag), ops.map(function (op) {
+        var summary = op.querySelector("summary");
+        return el("a", { href: "#" + op.id, "data-for": op.id },
+          summary.querySelector(".method").textContent.toUpperCase() + " " + summary.querySelector(".path").textContent);
+      })));
+    });
+
+    var schemas = (spec.components || {}).schemas || {};
+    if (Object.keys(schemas).length) {
+      append(main, el("section", { "class": "group" }, el("h2", { "class": "tag" }, "Schemas"),
+        Object.keys(schemas).sort().map(function (name) {
+          return el("details", { "class": "op", id: "schema-" + name, "data-search": name.toLowerCase() },
+            el("summary", null, el("span", { "class": "path" }, name)),
+            el("div", { "class": "body" }, schemaBlock({ $ref: "#/components/schemas/" + name })));
+        })));
+    }
+
+    openHash();
+  }
+
+  function openHash() {
+    var target = location.hash && document.getElementById(location.hash.slice(1));
+    if (target && target.tagName === "DETAILS") {
+      target.open = true;
+      target.scrollIntoView();
+    }
+  }
+
+  function applyFilter() {
+    var q = filter.value.trim().toLowerCase();
+    main.querySelectorAll("details.op").forEach(function (op) {
+      op.classList.toggle("hidden", q !== "" && op.getAttribute("data-search").indexOf(q) < 0);
+    });
+    nav.querySelectorAll("a").forEach(function (a) {
+      a.classList.toggle("hidden", document.getElementById(a.getAttribute("data-for")).classList.contains("hidden"));
+    });
+    document.querySelectorAll(".group").forEach(function (group) {
+      var items = group.querySelectorAll("details.op, a");
+      var visible = Array.prototype.some.call(items, function (item) { return !item.classList.contains("hidden"); });
+      group.classList.toggle("hidden", !visible);
+    });
+  }
+
+  filter.addEventListener("input", applyFilter);
+  window.addEventListener("hashchange", openHash);
+
+  fetch(main.getAttribute("data-spec"), { headers: { Accept: "application/json" } })
+    .then(function (res) {
+      if (!res.ok) {
+        throw new Error(res.status + " " + res.statusText);
+      }
+      return res.json();
+    })
+    .then(function (doc) {
+      spec = doc;
+      render();
+    })
+    .catch(function (err) {
+      main.textContent = "";
+      append(main, el("p", { "class": "status error" }, "Could not load " + main.getAttribute("data-spec") + ": " + err.message));
+    });
+})();
@@ -0,0 +1,403 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ValidationError is a value that does not match the specification.
+type ValidationError struct {
+	// In is where the value was found: "path", "query", "header",
+	// "cookie" or "body".
+	In string `json:"in"`
+	// Pointer is a JSON pointer (RFC 6901) to the value: "/limit" for a
+	// parameter, "/items/0/sku" inside a body, "" for the whole body.
+	Pointer string `json:"pointer"`
+	Message string `json:"message"`
+}
+
+// ValidationErrors lists every mismatch found in a request or response.
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	parts := make([]string, len(e))
+	for i, ve := range e {
+		parts[i] = fmt.Sprintf("%s %s: %s", ve.In, ve.Pointer, ve.Message)
+	}
+	return "openapi: " + strings.Join(parts, "; ")
+}
+
+// schemaMode tells request and response validation apart: readOnly
+// properties aren't required in requests, writeOnly ones in responses.
+type schemaMode int
+
+const (
+	requestMode schemaMode = iota
+	responseMode
+)
+
+// schemaValidator checks decoded JSON values (as produced by
+// encoding/json) against the JSON Schema subset used by OpenAPI:
+// $ref, type (and 3.0 nullable), enum, const, allOf/anyOf/oneOf/not,
+// string length, pattern and format, numeric bounds and multipleOf, array
+// items, size and uniqueness, and object properties, required,
+// additionalProperties and size.
+type schemaValidator struct {
+	spec *Spec
+	mode schemaMode
+	in   string
+	errs ValidationErrors
+}
+
+func (v *schemaValidator) fail(ptr, format string, args ...any) {
+	v.errs = append(v.errs, ValidationError{In: v.in, Pointer: ptr, Message: fmt.Sprintf(format, args...)})
+}
+
+// matches reports whether value satisfies schema without recording errors.
+func (v *schemaValidator) matches(schema, value any, ptr string, depth int) bool {
+	sub := &schemaValidator{spec: v.spec, mode: v.mode, in: v.in}
+	sub.validate(schema, value, ptr, depth)
+	return len(sub.errs) == 0
+}
+
+// maxSchemaDepth stops $ref cycles that never consume any input.
+const maxSchemaDepth = 64
+
+func (v *schemaValidator) validate(s, value any, ptr string, depth int) {
+	schema, ok := s.(map[string]any)
+	if !ok {
+		if b, isBool := s.(bool); isBool && !b {
+			v.fail(ptr, "is not allowed")
+		}
+		return
+	}
+	if depth > maxSchemaDepth {
+		v.fail(ptr, "schema nesting is too deep")
+		return
+	}
+
+	if ref, ok := schema["$ref"].(string); ok {
+		target, err := v.spec.resolve(ref)
+		if err != nil {
+			v.fail(ptr, "%v", err)
+			return
+		}
+		v.validate(target, value, ptr, depth+1)
+	}
+
+	if !v.checkType(schema, value, ptr) {
+		return
+	}
+	if enum, ok := schema["enum"].([]any); ok && !containsJSON(enum, value) {
+		v.fail(ptr, "must be one of %s", jsonList(enum))
+	}
+	if c, ok := schema["const"]; ok && !equalJSON(c, value) {
+		v.fail(ptr, "must be %s", jsonText(c))
+	}
+
+	for _, sub := range listOf(schema["allOf"]) {
+		v.validate(sub, value, ptr, depth+1)
+	}
+	if anyOf := listOf(schema["anyOf"]); len(anyOf) > 0 {
+		matched := false
+		for _, sub := range anyOf {
+			if v.matches(sub, value, ptr, depth+1) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			v.fail(ptr, "must match at least one of the allowed schemas")
+		}
+	}
+	if oneOf := listOf(schema["oneOf"]); len(oneOf) > 0 {
+		n := 0
+		for _, sub := range oneOf {
+			if v.matches(sub, value, ptr, depth+1) {
+				n++
+			}
+		}
+		if n != 1 {
+			v.fail(ptr, "must match exactly one of the allowed schemas, matches %d", n)
+		}
+	}
+	if not, ok := schema["not"]; ok && v.matches(not, value, ptr, depth+1) {
+		v.fail(ptr, "must not match the excluded schema")
+	}
+
+	switch value := value.(type) {
+	case string:
+		v.checkString(schema, value, ptr)
+	case float64:
+		v.checkNumber(schema, value, ptr)
+	case []any:
+		v.checkArray(schema, value, ptr, depth)
+	case map[string]any:
+		v.checkObject(schema, value, ptr, depth)
+	}
+}
+
+// checkType reports whether value has one of the schema's types; other
+// keywords are only checked when it does.
+func (v *schemaValidator) checkType(schema map[string]any, value any, ptr string) bool {
+	var types []string
+	switch t := schema["type"].(type) {
+	case string:
+		types = []string{t}
+	case []any:
+		for _, name := range t {
+			if s, ok := name.(string); ok {
+				types = append(types, s)
+			}
+		}
+	default:
+		return true
+	}
+	if nullable, _ := schema["nullable"].(bool); nullable {
+		types = append(types, "null")
+	}
+
+	actual := jsonType(value)
+	for _, t := range types {
+		if t == actual || (t == "number" && actual == "integer") {
+			return true
+		}
+	}
+	v.fail(ptr, "must be %s, got %s", strings.Join(types, " or "), actual)
+	return false
+}
+
+func jsonType(value any) string {
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if value == math.Trunc(value) && !math.IsInf(value, 0) {
+			return "integer"
+		}
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+func (v *schemaValidator) checkString(schema map[string]any, s, ptr string) {
+	n := utf8.RuneCountInString(s)
+	if min, ok := number(schema["minLength"]); ok && float64(n) < min {
+		v.fail(ptr, "must be at least %v characters", min)
+	}
+	if max, ok := number(schema["maxLength"]); ok && float64(n) > max {
+		v.fail(ptr, "must be at most %v characters", max)
+	}
+	if pattern, ok := schema["pattern"].(string); ok {
+		if re := v.spec.pattern(pattern); re != nil && !re.MatchString(s) {
+			v.fail(ptr, "must match pattern %s", pattern)
+		}
+	}
+
+	format, _ := schema["format"].(string)
+	valid := true
+	switch format {
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, s)
+		valid = err == nil
+	case "date":
+		_, err := time.Parse(time.DateOnly, s)
+		valid = err == nil
+	case "email":
+		addr, err := mail.ParseAddress(s)
+		valid = err == nil && addr.Address == s
+	case "uuid":
+		valid = uuidPattern.MatchString(s)
+	case "uri", "url":
+		u, err := url.Parse(s)
+		valid = err == nil && u.Scheme != ""
+	case "ipv4":
+		ip := net.ParseIP(s)
+		valid = ip != nil && ip.To4() != nil && !strings.Contains(s, ":")
+	case "ipv6":
+		valid = net.ParseIP(s) != nil && strings.Contains(s, ":")
+	}
+	if !valid {
+		v.fail(ptr, "must be a valid %s", format)
+	}
+}
+
+func (v *schemaValidator) checkNumber(schema map[string]any, n float64, ptr string) {
+	// OpenAPI 3.0 uses boolean exclusiveMinimum/Maximum modifying
+	// minimum/maximum; 3.1 uses numbers.
+	exclusiveMin, _ := schema["exclusiveMinimum"].(bool)
+	exclusiveMax, _ := schema["exclusiveMaximum"].(bool)
+	if min, ok := number(schema["minimum"]); ok {
+		if exclusiveMin && n <= min {
+			v.fail(ptr, "must be greater than %v", min)
+		} else if n < min {
+			v.fail(ptr, "must be at least %v", min)
+		}
+	}
+	if max, ok := number(schema["maximum"]); ok {
+		if exclusiveMax && n >= max {
+			v.fail(ptr, "must be less than %v", max)
+		} else if n > max {
+			v.fail(ptr, "must be at most %v", max)
+		}
+	}
+	if min, ok := number(schema["exclusiveMinimum"]); ok && n <= min {
+		v.fail(ptr, "must be greater than %v", min)
+	}
+	if max, ok := number(schema["exclusiveMaximum"]); ok && n >= max {
+		v.fail(ptr, "must be less than %v", max)
+	}
+	if m, ok := number(schema["multipleOf"]); ok && m > 0 {
+		if q := n / m; math.Abs(q-math.Round(q)) > 1e-9 {
+			v.fail(ptr, "must be a multiple of %v", m)
+		}
+	}
+}
+
+func (v *schemaValidator) checkArray(schema map[string]any, items []any, ptr string, depth int) {
+	if min, ok := number(schema["minItems"]); ok && float64(len(items)) < min {
+		v.fail(ptr, "must have at least %v items", min)
+	}
+	if max, ok := number(schema["maxItems"]); ok && float64(len(items)) > max {
+		v.fail(ptr, "must have at most %v items", max)
+	}
+	if unique, _ := schema["uniqueItems"].(bool); unique {
+		seen := make(map[string]int, len(items))
+		for i, item := range items {
+			key := jsonText(item)
+			if j, dup := seen[key]; dup {
+				v.fail(ptr+"/"+fmt.Sprint(i), "duplicates item %d", j)
+				continue
+			}
+			seen[key] = i
+		}
+	}
+	if itemSchema, ok := schema["items"]; ok {
+		for i, item := range items {
+			v.validate(itemSchema, item, fmt.Sprintf("%s/%d", ptr, i), depth+1)
+		}
+	}
+}
+
+func (v *schemaValidator) checkObject(schema map[string]any, obj map[string]any, ptr string, depth int) {
+	props, _ := schema["properties"].(map[string]any)
+
+	for _, name := range listOf(schema["required"]) {
+		name, ok := name.(string)
+		if !ok {
+			continue
+		}
+		if _, present := obj[name]; present || v.skipsRequired(props[name]) {
+			continue
+		}
+		v.fail(ptr+"/"+escapePointer(name), "is required")
+	}
+	if min, ok := number(schema["minProperties"]); ok && float64(len(obj)) < min {
+		v.fail(ptr, "must have at least %v properties", min)
+	}
+	if max, ok := number(schema["maxProperties"]); ok && float64(len(obj)) > max {
+		v.fail(ptr, "must have at most %v properties", max)
+	}
+
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	additional, hasAdditional := schema["additionalProperties"]
+	for _, name := range names {
+		p := ptr + "/" + escapePointer(name)
+		if propSchema, ok := props[name]; ok {
+			v.validate(propSchema, obj[name], p, depth+1)
+			continue
+		}
+		if hasAdditional {
+			if allowed, isBool := additional.(bool); isBool {
+				if !allowed {
+					v.fail(p, "is not allowed")
+				}
+				continue
+			}
+			v.validate(additional, obj[name], p, depth+1)
+		}
+	}
+}
+
+// skipsRequired reports whether a required property may be missing in the
+// current direction: readOnly ones in requests, writeOnly ones in
+// responses.
+func (v *schemaValidator) skipsRequired(prop any) bool {
+	schema, _ := prop.(map[string]any)
+	if ref, ok := schema["$ref"].(string); ok {
+		if target, err := v.spec.resolve(ref); err == nil {
+			schema, _ = target.(map[string]any)
+		}
+	}
+	key := "readOnly"
+	if v.mode == responseMode {
+		key = "writeOnly"
+	}
+	skip, _ := schema[key].(bool)
+	return skip
+}
+
+func escapePointer(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "~", "~0"), "/", "~1")
+}
+
+func number(v any) (float64, bool) {
+	f, ok := v.(float64)
+	return f, ok
+}
+
+func listOf(v any) []any {
+	list, _ := v.([]any)
+	return list
+}
+
+func jsonText(v any) string {
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+func jsonList(values []any) string {
+	parts := make([]string, len(values))
+	for i, value := range values {
+		parts[i] = jsonText(value)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// equalJSON compares decoded JSON values; maps are encoded with sorted
+// keys, so equal values encode identically.
+func equalJSON(a, b any) bool {
+	return jsonText(a) == jsonText(b)
+}
+
+func containsJSON(list []any, value any) bool {
+	for _, item := range list {
+		if equalJSON(item, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,452 @@
+// Package openapi generates an OpenAPI 3.1 document from the routes
+// registered on a router.Router and serves it as JSON and YAML.
+//
+//	openapi.Register(r, openapi.Config{Title: "Shop API", Version: "1.4.0"})
+//	// GET /openapi.json and GET /openapi.yaml
+//
+// Paths, methods and path parameters (with their constraints) come from
+// the route table. Routes registered with router.Typed also contribute
+// their request parameters, request body and response schemas, including
+// the limits declared in validate tags. Everything else is read from
+// route metadata:
+//
+//	r.GET("/users/:id<int>", show).
+//		Meta("summary", "Get a user").
+//		Meta("tags", []string{"Users"}).
+//		Meta("auth", true) // requires a JWT bearer token
+//
+// Recognised keys are "summary", "description", "operationId", "tags"
+// (string or []string), "deprecated" (bool), "auth" (bool) and "hidden"
+// (bool, leaves the route out). Route names are used as operation IDs when
+// "operationId" is not set.
+//
+// Docs serves an API reference UI for the document that is embedded in
+// the binary and works offline.
+//
+// In the other direction, LoadSpec reads a maintained document and
+// Validator checks requests (and, in development, responses) against it.
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/alejandrombjs/go-bastion-lib/pkg/router"
+)
+
+// Version is the OpenAPI version of generated documents.
+const Version = "3.1.0"
+
+// Config describes the API and where its document is served.
+type Config struct {
+	Title       string
+	Version     string
+	Description string
+	// Servers are base URLs of the API, e.g. "https://api.example.com".
+	Servers []string
+	// Path is where Register serves the document, without extension;
+	// ".json" and ".yaml" are appended. Defaults to "/openapi".
+	Path string
+	// APIVersion restricts the document to routes of one router.Versions
+	// version (plus unversioned routes). With header or media-type
+	// versioning, versions share paths, so it should be set.
+	APIVersion string
+	// Secured reports whether a route requires a JWT bearer token when it
+	// has no "auth" metadata, e.g. by path prefix. Defaults to false.
+	Secured func(router.RouteInfo) bool
+}
+
+// Document is an OpenAPI document.
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Servers    []Server             `json:"servers,omitempty"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+// Info is the document's info object.
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// Server is a base URL of the API.
+type Server struct {
+	URL string `json:"url"`
+}
+
+// PathItem maps lower-case HTTP methods to operations.
+type PathItem map[string]*Operation
+
+// Operation documents one method of a path.
+type Operation struct {
+	OperationID string                `json:"operationId,omitempty"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Deprecated  bool                  `json:"deprecated,omitempty"`
+	Parameters  []*Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+// Parameter is a path, query, header or cookie parameter.
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema"`
+}
+
+// RequestBody describes the body an operation accepts.
+type RequestBody struct {
+	Required bool                  `json:"required,omitempty"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+// Response describes one status an operation answers with.
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+// MediaType holds the schema of a body in one content type.
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components holds the schemas and security schemes referenced by
+// operations.
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas,omitempty"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme describes how clients authenticate.
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+// bearerAuth is the security scheme of routes protected by
+// middleware.JWTAuth.
+const bearerAuth = "bearerAuth"
+
+// Generate builds the document for the routes currently registered on r.
+// HEAD and OPTIONS routes are left out.
+func Generate(r *router.Router, cfg Config) *Document {
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    Info{Title: cfg.Title, Version: cfg.Version, Description: cfg.Description},
+		Paths:   make(map[string]*PathItem),
+	}
+	for _, url := range cfg.Servers {
+		doc.Servers = append(doc.Servers, Server{URL: url})
+	}
+
+	s := newSchemas()
+	s.components["Error"] = errorSchema()
+	s.components["AuthError"] = authErrorSchema()
+	secured := false
+
+	for _, rt := range r.Routes() {
+		if rt.Method == http.MethodHead || rt.Method == http.MethodOptions {
+			continue
+		}
+		if cfg.APIVersion != "" && rt.Version != "" && rt.Version != cfg.APIVersion {
+			continue
+		}
+
+		path, params := convertPath(rt.Path)
+		item := doc.Paths[path]
+		if item == nil {
+			item = &PathItem{}
+			doc.Paths[path] = item
+		}
+		method := strings.ToLower(rt.Method)
+		if (*item)[method] != nil {
+			// A route of another version already documents this path.
+			continue
+		}
+
+		op := operation(rt, params, s)
+		if requiresAuth(rt, cfg) {
+			secured = true
+			op.Security = []map[string][]string{{bearerAuth: {}}}
+			op.Responses["401"] = &Response{Description: "Unauthorized", Content: jsonContent(&Schema{Ref: "#/components/schemas/AuthError"})}
+		}
+		(*item)[method] = op
+	}
+
+	doc.Components.Schemas = s.components
+	if secured {
+		doc.Components.SecuritySchemes = map[string]*SecurityScheme{
+			bearerAuth: {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+		}
+	}
+	return doc
+}
+
+// JSON encodes the document as indented JSON.
+func (d *Document) JSON() ([]byte, error) {
+	return json.MarshalIndent(d, "", "  ")
+}
+
+// YAML encodes the document as YAML.
+func (d *Document) YAML() ([]byte, error) {
+	b, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+	return jsonToYAML(b)
+}
+
+// Register serves the document of r at cfg.Path+".json" and
+// cfg.Path+".yaml". The document is generated on the first request, so
+// routes registered after Register are included.
+func Register(r *router.Router, cfg Config) {
+	if cfg.Path == "" {
+		cfg.Path = "/openapi"
+	}
+
+	var (
+		once    sync.Once
+		jsonDoc []byte
+		yamlDoc []byte
+		genErr  error
+	)
+	generate := func() {
+		doc := Generate(r, cfg)
+		if jsonDoc, genErr = doc.JSON(); genErr != nil {
+			return
+		}
+		yamlDoc, genErr = doc.YAML()
+	}
+	serve := func(contentType string, body *[]byte) router.Handler {
+		return router.E(func(ctx *router.Context) error {
+			once.Do(generate)
+			if genErr != nil {
+				return genErr
+			}
+			ctx.ResponseWriter().Header().Set("Content-Type", contentType)
+			ctx.Status(http.StatusOK)
+			_, err := ctx.ResponseWriter().Write(*body)
+			return err
+		})
+	}
+
+	r.GET(cfg.Path+".json", serve("application/json", &jsonDoc)).Meta("hidden", true)
+	r.GET(cfg.Path+".yaml", serve("application/yaml", &yamlDoc)).Meta("hidden", true)
+}
+
+// convertPath turns a router pattern into an OpenAPI path template and
+// the parameters it declares: "/users/:id<int>/*rest" becomes
+// "/users/{id}/{rest}".
+func convertPath(pattern string) (string, []*Parameter) {
+	var params []*Parameter
+	segments := strings.Split(pattern, "/")
+	for i, segment := range segments {
+		switch {
+		case strings.HasPrefix(segment, ":"):
+			name, expr, _ := strings.Cut(segment[1:], "<")
+			params = append(params, &Parameter{Name: name, In: "path", Required: true, Schema: constraintSchema(strings.TrimSuffix(expr, ">"))})
+			segments[i] = "{" + name + "}"
+		case strings.HasPrefix(segment, "*"):
+			name := segment[1:]
+			params = append(params, &Parameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"}})
+			segments[i] = "{" + name + "}"
+		}
+	}
+	return strings.Join(segments, "/"), params
+}
+
+// constraintSchema describes a :param<constraint>.
+func constraintSchema(expr string) *Schema {
+	switch expr {
+	case "":
+		return &Schema{Type: "string"}
+	case "int":
+		return &Schema{Type: "integer", Format: "int64"}
+	case "uint":
+		zero := 0.0
+		return &Schema{Type: "integer", Minimum: &zero}
+	case "alpha":
+		return &Schema{Type: "string", Pattern: "^[a-zA-Z]+$"}
+	case "uuid":
+		return &Schema{Type: "string", Format: "uuid"}
+	}
+	return &Schema{Type: "string", Pattern: "^(?:" + expr + ")$"}
+}
+
+func operation(rt router.RouteInfo, pathParams []*Parameter, s *schemas) *Operation {
+	op := &Operation{Responses: make(map[string]*Response)}
+	op.Summary, _ = rt.Meta["summary"].(string)
+	op.Description, _ = rt.Meta["description"].(string)
+	op.Deprecated, _ = rt.Meta["deprecated"].(bool)
+	op.OperationID, _ = rt.Meta["operationId"].(string)
+	if op.OperationID == "" {
+		op.OperationID = rt.Name
+	}
+	switch tags := rt.Meta["tags"].(type) {
+	case string:
+		op.Tags = []string{tags}
+	case []string:
+		op.Tags = tags
+	}
+
+	op.Parameters = pathParams
+	if rt.Request != nil {
+		requestParams(op, rt.Request, s)
+	}
+	sort.SliceStable(op.Parameters, func(i, j int) bool {
+		return paramOrder(op.Parameters[i].In) < paramOrder(op.Parameters[j].In)
+	})
+
+	status, desc := "200", "OK"
+	if rt.Method == http.MethodPost {
+		status, desc = "201", "Created"
+	}
+	var body *Schema
+	if rt.Response != nil {
+		out := rt.Response
+		if out == reflect.TypeOf(struct{}{}) {
+			status, desc = "204", "No Content"
+		} else {
+			body = s.of(out)
+			if sc, ok := reflect.Zero(out).Interface().(router.StatusCoder); ok && out.Kind() != reflect.Pointer {
+				status, desc = statusKey(sc.StatusCode())
+			}
+		}
+	}
+	op.Responses[status] = &Response{Description: desc}
+	if body != nil {
+		op.Responses[status].Content = jsonContent(body)
+	}
+
+	errRef := jsonContent(&Schema{Ref: "#/components/schemas/Error"})
+	if op.RequestBody != nil || len(op.Parameters) > len(pathParams) {
+		op.Responses["422"] = &Response{Description: "Validation failed", Content: errRef}
+	}
+	op.Responses["default"] = &Response{Description: "Error", Content: errRef}
+	return op
+}
+
+// requestParams adds the path, query, header and cookie parameters and
+// the JSON body described by the input type of a Typed route.
+func requestParams(op *Operation, in reflect.Type, s *schemas) {
+	for in.Kind() == reflect.Pointer {
+		in = in.Elem()
+	}
+	if in.Kind() != reflect.Struct || in.NumField() == 0 {
+		return
+	}
+
+	var walk func(t reflect.Type)
+	walk = func(t reflect.Type) {
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			if sf.Anonymous && sf.Type.Kind() == reflect.Struct && !hasBindingTag(sf) {
+				walk(sf.Type)
+				continue
+			}
+			for _, loc := range []string{"path", "query", "header", "cookie"} {
+				name, ok := sf.Tag.Lookup(loc)
+				if !ok || name == "-" {
+					continue
+				}
+				if name == "" {
+					name = sf.Name
+				}
+				schema := s.of(sf.Type)
+				required := applyValidate(schema, sf.Tag.Get("validate"), sf.Type)
+				if loc == "path" {
+					// Already declared by the pattern; refine its schema.
+					for _, p := range op.Parameters {
+						if p.In == "path" && p.Name == name {
+							p.Schema = schema
+						}
+					}
+					continue
+				}
+				op.Parameters = append(op.Parameters, &Parameter{Name: name, In: loc, Required: required, Schema: schema})
+			}
+		}
+	}
+	walk(in)
+
+	body := s.object(in, true)
+	if len(body.Properties) == 0 {
+		return
+	}
+	schema := body
+	if in.Name() != "" {
+		schema = s.ref(in, func() *Schema { return body })
+	}
+	op.RequestBody = &RequestBody{Required: true, Content: jsonContent(schema)}
+}
+
+func paramOrder(in string) int {
+	switch in {
+	case "path":
+		return 0
+	case "query":
+		return 1
+	case "header":
+		return 2
+	}
+	return 3
+}
+
+func statusKey(code int) (string, string) {
+	return strconv.Itoa(code), http.StatusText(code)
+}
+
+func requiresAuth(rt router.RouteInfo, cfg Config) bool {
+	if auth, ok := rt.Meta["auth"].(bool); ok {
+		return auth
+	}
+	return cfg.Secured != nil && cfg.Secured(rt)
+}
+
+func jsonContent(schema *Schema) map[string]*MediaType {
+	return map[string]*MediaType{"application/json": {Schema: schema}}
+}
+
+// errorSchema describes the body written by response.Error and the
+// router's ErrorHandler.
+func errorSchema() *Schema {
+	return &Schema{
+		Type:     "object",
+		Required: []string{"error"},
+		Properties: map[string]*Schema{
+			"error": {
+				Type:     "object",
+				Required: []string{"code", "message"},
+				Properties: map[string]*Schema{
+					"code":       {Type: "string"},
+					"message":    {Type: "string"},
+					"details":    {},
+					"request_id": {Type: "string"},
+				},
+			},
+		},
+	}
+}
+
+// authErrorSchema describes the body written by middleware.JWTAuth.
+func authErrorSchema() *Schema {
+	return &Schema{
+		Type:       "object",
+		Required:   []string{"error"},
+		Properties: map[string]*Schema{"error": {Type: "string", Enum: []any{"unauthorized", "token_expired"}}},
+	}
+}
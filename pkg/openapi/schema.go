@@ -0,0 +1,270 @@
+package openapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema is an OpenAPI 3.1 (JSON Schema 2020-12) schema object.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+var (
+	timeType            = reflect.TypeOf(time.Time{})
+	durationType        = reflect.TypeOf(time.Duration(0))
+	rawMessageType      = reflect.TypeOf(json.RawMessage(nil))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// bindingTags are the struct tags router.Context.Bind reads from outside
+// the JSON body.
+var bindingTags = []string{"path", "query", "header", "cookie", "form"}
+
+// schemas builds component schemas for Go types.
+type schemas struct {
+	components map[string]*Schema
+	names      map[reflect.Type]string
+}
+
+func newSchemas() *schemas {
+	return &schemas{components: make(map[string]*Schema), names: make(map[reflect.Type]string)}
+}
+
+// of returns the schema of t, a $ref for named struct types.
+func (s *schemas) of(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case durationType:
+		return &Schema{Type: "integer", Format: "int64", Description: "nanoseconds"}
+	case rawMessageType:
+		return &Schema{}
+	}
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return &Schema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		zero := 0.0
+		return &Schema{Type: "integer", Minimum: &zero}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: s.of(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.of(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.object(t, false)
+		}
+		return s.ref(t, func() *Schema { return s.object(t, false) })
+	}
+	return &Schema{}
+}
+
+// ref registers t as a component built by build and returns a $ref to it.
+func (s *schemas) ref(t reflect.Type, build func() *Schema) *Schema {
+	name, ok := s.names[t]
+	if !ok {
+		name = s.componentName(t)
+		s.names[t] = name
+		// Reserve the name first so recursive types terminate.
+		s.components[name] = &Schema{}
+		*s.components[name] = *build()
+	}
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// componentName is the type name, qualified by its package when another
+// type already uses it.
+func (s *schemas) componentName(t reflect.Type) string {
+	name := t.Name()
+	if i := strings.IndexByte(name, '['); i >= 0 {
+		// Generic instantiation: Page[main.User] -> Page_User.
+		args := strings.NewReplacer("*", "", "[", "", "]", "", ",", "_", " ", "").Replace(name[i:])
+		if dot := strings.LastIndexByte(args, '.'); dot >= 0 {
+			args = args[dot+1:]
+		}
+		name = name[:i] + "_" + args
+	}
+	if _, taken := s.components[name]; !taken {
+		return name
+	}
+	pkg := t.PkgPath()
+	if slash := strings.LastIndexByte(pkg, '/'); slash >= 0 {
+		pkg = pkg[slash+1:]
+	}
+	return pkg + "." + name
+}
+
+// object describes the JSON body of a struct. With bodyOnly, fields bound
+// from the path, query, headers, cookies or form are left out.
+func (s *schemas) object(t reflect.Type, bodyOnly bool) *Schema {
+	obj := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	s.addFields(obj, t, bodyOnly)
+	return obj
+}
+
+func (s *schemas) addFields(obj *Schema, t reflect.Type, bodyOnly bool) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() && !sf.Anonymous {
+			continue
+		}
+		if bodyOnly && hasBindingTag(sf) {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(sf.Tag.Get("json"), ",")
+		if name == "-" && opts == "" {
+			continue
+		}
+		ft := sf.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if sf.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			s.addFields(obj, ft, bodyOnly)
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+
+		field := s.of(sf.Type)
+		if applyValidate(field, sf.Tag.Get("validate"), sf.Type) {
+			obj.Required = append(obj.Required, name)
+		}
+		obj.Properties[name] = field
+	}
+}
+
+func hasBindingTag(sf reflect.StructField) bool {
+	for _, tag := range bindingTags {
+		if _, ok := sf.Tag.Lookup(tag); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// applyValidate adds the constraints of a validate tag to schema and
+// reports whether the field is required. Constraints can't be added next
+// to a $ref, so referenced schemas only contribute "required".
+func applyValidate(schema *Schema, tag string, t reflect.Type) (required bool) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	for _, part := range strings.Split(tag, ",") {
+		rule, param, _ := strings.Cut(strings.TrimSpace(part), "=")
+		if rule == "required" {
+			required = true
+			continue
+		}
+		if schema.Ref != "" {
+			continue
+		}
+
+		n, numErr := strconv.ParseFloat(param, 64)
+		switch rule {
+		case "min", "max", "len":
+			if numErr != nil {
+				continue
+			}
+			setBound(schema, t, rule, n)
+		case "oneof":
+			for _, option := range strings.Fields(param) {
+				if schema.Type == "integer" {
+					if v, err := strconv.ParseInt(option, 10, 64); err == nil {
+						schema.Enum = append(schema.Enum, v)
+						continue
+					}
+				}
+				schema.Enum = append(schema.Enum, option)
+			}
+		case "email":
+			schema.Format = "email"
+		case "url":
+			schema.Format = "uri"
+		case "uuid":
+			schema.Format = "uuid"
+		case "alpha":
+			schema.Pattern = "^[a-zA-Z]+$"
+		case "alphanum":
+			schema.Pattern = "^[a-zA-Z0-9]+$"
+		}
+	}
+	return required
+}
+
+// setBound maps min, max and len onto the keyword matching the type:
+// minimum/maximum for numbers, minLength/maxLength for strings and
+// minItems/maxItems for collections.
+func setBound(schema *Schema, t reflect.Type, rule string, n float64) {
+	lower, upper := rule == "min" || rule == "len", rule == "max" || rule == "len"
+	count := int(n)
+	switch t.Kind() {
+	case reflect.String:
+		if lower {
+			schema.MinLength = &count
+		}
+		if upper {
+			schema.MaxLength = &count
+		}
+	case reflect.Slice, reflect.Array, reflect.Map:
+		if lower {
+			schema.MinItems = &count
+		}
+		if upper {
+			schema.MaxItems = &count
+		}
+	default:
+		if lower {
+			schema.Minimum = &n
+		}
+		if upper {
+			schema.Maximum = &n
+		}
+	}
+}
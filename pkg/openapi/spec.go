@@ -0,0 +1,253 @@
+package openapi
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Spec is an OpenAPI 3.x document loaded for request validation.
+type Spec struct {
+	root       map[string]any
+	operations []*specOperation
+	// bases are the path prefixes of the document's servers, e.g. "/v1".
+	bases    []string
+	patterns sync.Map // pattern -> *regexp.Regexp, nil if invalid
+}
+
+// specOperation is one method of one path, with $refs of its parameters
+// and request body resolved.
+type specOperation struct {
+	method     string
+	path       string
+	segments   []string
+	templated  int // number of templated segments; fewer wins
+	parameters []map[string]any
+	body       map[string]any
+	responses  map[string]any
+}
+
+// LoadSpec reads and parses the OpenAPI document at path, in YAML or JSON.
+func LoadSpec(path string) (*Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("openapi: %w", err)
+	}
+	spec, err := ParseSpec(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w (%s)", err, path)
+	}
+	return spec, nil
+}
+
+// ParseSpec parses an OpenAPI 3.x document in YAML or JSON. Only local
+// references ("#/components/...") are supported.
+func ParseSpec(data []byte) (*Spec, error) {
+	doc, err := parseYAML(data)
+	if err != nil {
+		return nil, err
+	}
+	root, ok := doc.(map[string]any)
+	if !ok {
+		return nil, errors.New("openapi: document is not a mapping")
+	}
+	if version, _ := root["openapi"].(string); !strings.HasPrefix(version, "3.") {
+		return nil, fmt.Errorf("openapi: unsupported document version %v, want 3.x", root["openapi"])
+	}
+
+	s := &Spec{root: root}
+	for _, server := range listOf(root["servers"]) {
+		server, _ := server.(map[string]any)
+		raw, _ := server["url"].(string)
+		if u, err := url.Parse(raw); err == nil {
+			if base := strings.TrimSuffix(u.Path, "/"); base != "" {
+				s.bases = append(s.bases, base)
+			}
+		}
+	}
+
+	paths, _ := root["paths"].(map[string]any)
+	for path, item := range paths {
+		item, err := s.resolveMap(item)
+		if err != nil {
+			return nil, fmt.Errorf("openapi: paths %s: %w", path, err)
+		}
+		shared := listOf(item["parameters"])
+		for method, op := range item {
+			if !isHTTPMethod(method) {
+				continue
+			}
+			op, _ := op.(map[string]any)
+			compiled, err := s.compile(strings.ToUpper(method), path, op, shared)
+			if err != nil {
+				return nil, fmt.Errorf("openapi: %s %s: %w", strings.ToUpper(method), path, err)
+			}
+			s.operations = append(s.operations, compiled)
+		}
+	}
+	// Literal segments take precedence over templated ones.
+	sort.SliceStable(s.operations, func(i, j int) bool {
+		a, b := s.operations[i], s.operations[j]
+		if a.templated != b.templated {
+			return a.templated < b.templated
+		}
+		return a.path < b.path
+	})
+	return s, nil
+}
+
+func isHTTPMethod(key string) bool {
+	switch key {
+	case "get", "put", "post", "delete", "options", "head", "patch", "trace":
+		return true
+	}
+	return false
+}
+
+func (s *Spec) compile(method, path string, op map[string]any, shared []any) (*specOperation, error) {
+	c := &specOperation{method: method, path: path, segments: strings.Split(strings.Trim(path, "/"), "/")}
+	for _, segment := range c.segments {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			c.templated++
+		}
+	}
+
+	// Operation parameters override path-level ones with the same name and
+	// location.
+	byKey := make(map[string]int)
+	for _, list := range [][]any{shared, listOf(op["parameters"])} {
+		for _, p := range list {
+			param, err := s.resolveMap(p)
+			if err != nil {
+				return nil, err
+			}
+			name, _ := param["name"].(string)
+			in, _ := param["in"].(string)
+			key := in + ":" + strings.ToLower(name)
+			if i, ok := byKey[key]; ok {
+				c.parameters[i] = param
+				continue
+			}
+			byKey[key] = len(c.parameters)
+			c.parameters = append(c.parameters, param)
+		}
+	}
+
+	if body, ok := op["requestBody"]; ok {
+		resolved, err := s.resolveMap(body)
+		if err != nil {
+			return nil, err
+		}
+		c.body = resolved
+	}
+	c.responses, _ = op["responses"].(map[string]any)
+	return c, nil
+}
+
+// match finds the operation for a request. It returns the path parameters
+// taken from the request path.
+func (s *Spec) match(method, path string) (*specOperation, map[string]string) {
+	candidates := []string{path}
+	for _, base := range s.bases {
+		if rest, ok := strings.CutPrefix(path, base); ok && (rest == "" || rest[0] == '/') {
+			candidates = append(candidates, rest)
+		}
+	}
+
+	for _, candidate := range candidates {
+		segments := strings.Split(strings.Trim(candidate, "/"), "/")
+		for _, op := range s.operations {
+			if op.method != method || len(op.segments) != len(segments) {
+				continue
+			}
+			if params, ok := op.matchSegments(segments); ok {
+				return op, params
+			}
+		}
+	}
+	return nil, nil
+}
+
+func (op *specOperation) matchSegments(segments []string) (map[string]string, bool) {
+	var params map[string]string
+	for i, want := range op.segments {
+		if strings.HasPrefix(want, "{") && strings.HasSuffix(want, "}") {
+			if segments[i] == "" {
+				return nil, false
+			}
+			if params == nil {
+				params = make(map[string]string)
+			}
+			params[want[1:len(want)-1]] = segments[i]
+			continue
+		}
+		if want != segments[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// resolve follows a local reference such as "#/components/schemas/User".
+func (s *Spec) resolve(ref string) (any, error) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, fmt.Errorf("unsupported external reference %q", ref)
+	}
+	var node any = s.root
+	for _, part := range strings.Split(strings.TrimPrefix(ref[1:], "/"), "/") {
+		if part == "" {
+			continue
+		}
+		if unescaped, err := url.PathUnescape(part); err == nil {
+			part = unescaped
+		}
+		part = strings.ReplaceAll(strings.ReplaceAll(part, "~1", "/"), "~0", "~")
+		m, ok := node.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("unresolvable reference %q", ref)
+		}
+		if node, ok = m[part]; !ok {
+			return nil, fmt.Errorf("unresolvable reference %q", ref)
+		}
+	}
+	return node, nil
+}
+
+// resolveMap follows $ref chains of parameter, request body and response
+// objects.
+func (s *Spec) resolveMap(v any) (map[string]any, error) {
+	for i := 0; i < maxSchemaDepth; i++ {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil, errors.New("expected an object")
+		}
+		ref, ok := m["$ref"].(string)
+		if !ok {
+			return m, nil
+		}
+		var err error
+		if v, err = s.resolve(ref); err != nil {
+			return nil, err
+		}
+	}
+	return nil, errors.New("reference cycle")
+}
+
+// pattern compiles a schema pattern once. Invalid patterns (e.g. ECMA-262
+// syntax RE2 lacks) are ignored.
+func (s *Spec) pattern(expr string) *regexp.Regexp {
+	if re, ok := s.patterns.Load(expr); ok {
+		return re.(*regexp.Regexp)
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		re = nil
+	}
+	s.patterns.Store(expr, re)
+	return re
+}
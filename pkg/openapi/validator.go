@@ -0,0 +1,395 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/alejandrombjs/go-bastion-lib/pkg/router"
+)
+
+// ValidatorConfig configures Validator.
+type ValidatorConfig struct {
+	// ValidateResponses also checks the status and JSON body of responses
+	// and logs contract violations; clients still get the response as
+	// written. It buffers response bodies, so enable it in development and
+	// tests only.
+	ValidateResponses bool
+	// Logger receives response violations. Defaults to log.Default().
+	Logger *log.Logger
+	// MaxBodyBytes limits the JSON request body read for validation and
+	// the response body captured. Defaults to 1 MiB. Bodies without a JSON
+	// schema, such as file uploads, are not read and not limited.
+	MaxBodyBytes int64
+}
+
+// Validator returns middleware that checks requests against the matching
+// operation of spec:
+//
+//	spec, err := openapi.LoadSpec("docs/openapi.yaml")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	r.Use(openapi.Validator(spec, openapi.ValidatorConfig{ValidateResponses: cfg.Env != "production"}))
+//
+// Path, query, header and cookie parameters are converted to their schema
+// type and validated, as is a JSON request body. Mismatches are passed to
+// the router's ErrorHandler as a 400 "invalid_request" HTTPError whose
+// details are ValidationErrors; a body of a media type the operation
+// doesn't accept gets 415. Requests that match no operation are passed
+// through, leaving 404 and 405 to the router.
+func Validator(spec *Spec, cfg ValidatorConfig) router.Middleware {
+	if cfg.Logger == nil {
+		cfg.Logger = log.Default()
+	}
+	if cfg.MaxBodyBytes <= 0 {
+		cfg.MaxBodyBytes = 1 << 20
+	}
+
+	return func(next router.Handler) router.Handler {
+		return func(ctx *router.Context) {
+			req := ctx.Request()
+			op, pathParams := spec.match(req.Method, req.URL.Path)
+			if op == nil {
+				next(ctx)
+				return
+			}
+			if err := spec.validateRequest(req, op, pathParams, cfg.MaxBodyBytes); err != nil {
+				ctx.Error(err)
+				return
+			}
+			if !cfg.ValidateResponses {
+				next(ctx)
+				return
+			}
+
+			rec := &responseCapture{status: http.StatusOK, limit: cfg.MaxBodyBytes}
+			router.WrapM(func(h http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					rec.ResponseWriter = w
+					h.ServeHTTP(rec, r)
+				})
+			})(next)(ctx)
+			if rec.truncated {
+				return
+			}
+
+			requestID, _ := ctx.GetString("requestID")
+			contentType := ctx.ResponseWriter().Header().Get("Content-Type")
+			for _, ve := range spec.validateResponse(op, rec.status, contentType, rec.body.Bytes()) {
+				cfg.Logger.Printf("[%s] openapi: %s %s %d response violates the spec: %s %s: %s",
+					requestID, req.Method, req.URL.Path, rec.status, ve.In, ve.Pointer, ve.Message)
+			}
+		}
+	}
+}
+
+var errRequestInvalid = router.NewHTTPError(http.StatusBadRequest, "invalid_request", "Request does not match the API specification")
+
+// validateRequest checks the parameters and body of req.
+func (s *Spec) validateRequest(req *http.Request, op *specOperation, pathParams map[string]string, maxBytes int64) error {
+	var errs ValidationErrors
+	for _, param := range op.parameters {
+		errs = append(errs, s.validateParam(req, param, pathParams)...)
+	}
+
+	if op.body != nil {
+		bodyErrs, err := s.validateBody(req, op.body, maxBytes)
+		if err != nil {
+			return err
+		}
+		errs = append(errs, bodyErrs...)
+	}
+
+	if len(errs) > 0 {
+		return errRequestInvalid.WithDetails(errs).Wrap(errs)
+	}
+	return nil
+}
+
+func (s *Spec) validateParam(req *http.Request, param map[string]any, pathParams map[string]string) ValidationErrors {
+	name, _ := param["name"].(string)
+	in, _ := param["in"].(string)
+	required, _ := param["required"].(bool)
+	ptr := "/" + escapePointer(name)
+
+	var raw []string
+	switch in {
+	case "path":
+		if v, ok := pathParams[name]; ok {
+			raw = []string{v}
+		}
+		required = true
+	case "query":
+		raw = req.URL.Query()[name]
+	case "header":
+		raw = req.Header.Values(name)
+	case "cookie":
+		if c, err := req.Cookie(name); err == nil {
+			raw = []string{c.Value}
+		}
+	default:
+		return nil
+	}
+	if len(raw) == 0 {
+		if required {
+			return ValidationErrors{{In: in, Pointer: ptr, Message: "is required"}}
+		}
+		return nil
+	}
+
+	schema, _ := param["schema"].(map[string]any)
+	if schema == nil {
+		return nil
+	}
+	v := &schemaValidator{spec: s, mode: requestMode, in: in}
+	value, ok := v.coerce(schema, raw, param, ptr)
+	if ok {
+		v.validate(schema, value, ptr, 0)
+	}
+	return v.errs
+}
+
+// coerce converts the raw strings of a parameter to the JSON value its
+// schema describes. Arrays come from repeated query parameters or, for
+// other locations and explode: false, from comma-separated values.
+func (v *schemaValidator) coerce(schema map[string]any, raw []string, param map[string]any, ptr string) (any, bool) {
+	resolved := schema
+	if ref, ok := schema["$ref"].(string); ok {
+		if target, err := v.spec.resolve(ref); err == nil {
+			resolved, _ = target.(map[string]any)
+		}
+	}
+
+	if schemaType(resolved) != "array" {
+		return v.scalar(schemaType(resolved), raw[0], ptr)
+	}
+
+	explode, set := param["explode"].(bool)
+	if !set {
+		style, _ := param["style"].(string)
+		explode = param["in"] == "query" && (style == "" || style == "form")
+	}
+	if !explode {
+		raw = strings.Split(raw[0], ",")
+	}
+	itemType := ""
+	if items, ok := resolved["items"].(map[string]any); ok {
+		if ref, isRef := items["$ref"].(string); isRef {
+			if target, err := v.spec.resolve(ref); err == nil {
+				items, _ = target.(map[string]any)
+			}
+		}
+		itemType = schemaType(items)
+	}
+	list := make([]any, 0, len(raw))
+	valid := true
+	for i, s := range raw {
+		item, ok := v.scalar(itemType, s, ptr+"/"+strconv.Itoa(i))
+		valid = valid && ok
+		list = append(list, item)
+	}
+	return list, valid
+}
+
+func (v *schemaValidator) scalar(typ, s, ptr string) (any, bool) {
+	switch typ {
+	case "integer", "number":
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			v.fail(ptr, "must be %s", typ)
+			return nil, false
+		}
+		return f, true
+	case "boolean":
+		b, err := strconv.ParseBool(s)
+		if err != nil || (s != "true" && s != "false") {
+			v.fail(ptr, "must be true or false")
+			return nil, false
+		}
+		return b, true
+	}
+	return s, true
+}
+
+// schemaType returns the first non-null type of a schema.
+func schemaType(schema map[string]any) string {
+	switch t := schema["type"].(type) {
+	case string:
+		return t
+	case []any:
+		for _, name := range t {
+			if s, ok := name.(string); ok && s != "null" {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+// validateBody checks a request body against a request body object. It
+// returns an HTTPError for a missing or unsupported media type.
+// validateBody checks the body of req against the requestBody object
+// spec. Only a body validated against a JSON schema is read, up to
+// maxBytes, and replaced so handlers can still decode it. Any other body,
+// such as a multipart upload, is left unread for the handler.
+func (s *Spec) validateBody(req *http.Request, spec map[string]any, maxBytes int64) (ValidationErrors, error) {
+	contentType := req.Header.Get("Content-Type")
+	content, _ := spec["content"].(map[string]any)
+	media, mediaType, ok := lookupMedia(content, contentType)
+	schema, hasSchema := media["schema"]
+
+	if !ok || !hasSchema || !isJSONMediaType(mediaType) {
+		if req.ContentLength == 0 {
+			return missingBody(spec), nil
+		}
+		if !ok {
+			return nil, router.NewHTTPError(http.StatusUnsupportedMediaType, "unsupported_media_type",
+				"Content-Type "+strconv.Quote(contentType)+" is not accepted by this operation")
+		}
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(req.Body, maxBytes+1))
+	if err != nil {
+		return nil, router.NewHTTPError(http.StatusBadRequest, "invalid_request", "Could not read the request body").Wrap(err)
+	}
+	if int64(len(body)) > maxBytes {
+		return nil, router.NewHTTPError(http.StatusRequestEntityTooLarge, "body_too_large", "Request body is too large")
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		return missingBody(spec), nil
+	}
+	return s.validateJSON(schema, body, "body", requestMode), nil
+}
+
+// missingBody reports an empty body when spec requires one.
+func missingBody(spec map[string]any) ValidationErrors {
+	if required, _ := spec["required"].(bool); required {
+		return ValidationErrors{{In: "body", Pointer: "", Message: "is required"}}
+	}
+	return nil
+}
+
+// validateJSON decodes body and validates it against schema.
+func (s *Spec) validateJSON(schema any, body []byte, in string, mode schemaMode) ValidationErrors {
+	var value any
+	if err := json.Unmarshal(body, &value); err != nil {
+		return ValidationErrors{{In: in, Pointer: "", Message: "must be valid JSON: " + err.Error()}}
+	}
+	v := &schemaValidator{spec: s, mode: mode, in: in}
+	v.validate(schema, value, "", 0)
+	return v.errs
+}
+
+// lookupMedia finds the media type object for contentType in a content
+// map, trying the exact type, then "type/*", then "*/*".
+func lookupMedia(content map[string]any, contentType string) (map[string]any, string, bool) {
+	if len(content) == 0 {
+		return nil, "", true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, "", false
+	}
+	major, _, _ := strings.Cut(mediaType, "/")
+	for _, key := range []string{mediaType, major + "/*", "*/*"} {
+		for declared, media := range content {
+			if strings.EqualFold(declared, key) {
+				m, _ := media.(map[string]any)
+				return m, mediaType, true
+			}
+		}
+	}
+	return nil, "", false
+}
+
+func isJSONMediaType(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// validateResponse checks a response status and body against op.
+func (s *Spec) validateResponse(op *specOperation, status int, contentType string, body []byte) ValidationErrors {
+	code := strconv.Itoa(status)
+	var spec any
+	for _, key := range []string{code, code[:1] + "XX", code[:1] + "xx", "default"} {
+		if r, ok := op.responses[key]; ok {
+			spec = r
+			break
+		}
+	}
+	if spec == nil {
+		return ValidationErrors{{In: "status", Pointer: "", Message: "status " + code + " is not documented"}}
+	}
+	resolved, err := s.resolveMap(spec)
+	if err != nil {
+		return ValidationErrors{{In: "status", Pointer: "", Message: err.Error()}}
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+
+	content, _ := resolved["content"].(map[string]any)
+	if len(content) == 0 {
+		return ValidationErrors{{In: "body", Pointer: "", Message: "status " + code + " is documented without a body"}}
+	}
+	media, mediaType, ok := lookupMedia(content, contentType)
+	if !ok {
+		return ValidationErrors{{In: "header", Pointer: "/Content-Type", Message: strconv.Quote(contentType) + " is not documented for status " + code}}
+	}
+	schema, hasSchema := media["schema"]
+	if !hasSchema || !isJSONMediaType(mediaType) {
+		return nil
+	}
+	return s.validateJSON(schema, body, "body", responseMode)
+}
+
+// responseCapture copies the status and body of a response while passing
+// them through.
+type responseCapture struct {
+	http.ResponseWriter
+	status    int
+	wrote     bool
+	body      bytes.Buffer
+	limit     int64
+	truncated bool
+}
+
+func (rc *responseCapture) WriteHeader(code int) {
+	if !rc.wrote && (code < 100 || code >= 200 || code == http.StatusSwitchingProtocols) {
+		rc.status = code
+		rc.wrote = true
+	}
+	rc.ResponseWriter.WriteHeader(code)
+}
+
+func (rc *responseCapture) Write(b []byte) (int, error) {
+	rc.wrote = true
+	if !rc.truncated {
+		if int64(rc.body.Len()+len(b)) > rc.limit {
+			rc.truncated = true
+			rc.body.Reset()
+		} else {
+			rc.body.Write(b)
+		}
+	}
+	return rc.ResponseWriter.Write(b)
+}
+
+// Flush keeps streaming responses working; they are not validated.
+func (rc *responseCapture) Flush() {
+	rc.truncated = true
+	_ = http.NewResponseController(rc.ResponseWriter).Flush()
+}
+
+// Unwrap returns the underlying writer, for http.ResponseController.
+func (rc *responseCapture) Unwrap() http.ResponseWriter {
+	return rc.ResponseWriter
+}
@@ -0,0 +1,669 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// yamlNode is a decoded JSON value that keeps object keys in order.
+type yamlNode struct {
+	keys   []string    // object keys, when object is true
+	values []*yamlNode // object values or array items
+	object bool
+	array  bool
+	scalar string // YAML text of a scalar
+}
+
+// jsonToYAML re-encodes a JSON document as block-style YAML, keeping the
+// key order of the JSON encoding.
+func jsonToYAML(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	root, err := decodeYAMLNode(dec)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	writeYAML(&buf, root, 0)
+	return buf.Bytes(), nil
+}
+
+func decodeYAMLNode(dec *json.Decoder) (*yamlNode, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok := tok.(type) {
+	case json.Delim:
+		n := &yamlNode{object: tok == '{', array: tok == '['}
+		for dec.More() {
+			if n.object {
+				key, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				n.keys = append(n.keys, key.(string))
+			}
+			v, err := decodeYAMLNode(dec)
+			if err != nil {
+				return nil, err
+			}
+			n.values = append(n.values, v)
+		}
+		if _, err := dec.Token(); err != nil { // closing delimiter
+			return nil, err
+		}
+		return n, nil
+	case string:
+		return &yamlNode{scalar: yamlString(tok)}, nil
+	case json.Number:
+		return &yamlNode{scalar: tok.String()}, nil
+	case bool:
+		return &yamlNode{scalar: fmt.Sprint(tok)}, nil
+	case nil:
+		return &yamlNode{scalar: "null"}, nil
+	}
+	return nil, fmt.Errorf("openapi: unexpected JSON token %v", tok)
+}
+
+// writeYAML writes the entries of an object or array at indent; scalars
+// and empty collections are written inline by their parent.
+func writeYAML(buf *bytes.Buffer, n *yamlNode, indent int) {
+	pad := strings.Repeat(" ", indent)
+	for i, v := range n.values {
+		if n.object {
+			buf.WriteString(pad + yamlString(n.keys[i]) + ":")
+		} else {
+			buf.WriteString(pad + "-")
+		}
+		switch {
+		case inline(v) != "":
+			buf.WriteString(" " + inline(v) + "\n")
+		case n.array && v.object:
+			// "- key: value" with the remaining keys aligned under the first.
+			var item bytes.Buffer
+			writeYAML(&item, v, indent+2)
+			buf.WriteString(" " + strings.TrimPrefix(item.String(), pad+"  "))
+		default:
+			buf.WriteString("\n")
+			writeYAML(buf, v, indent+2)
+		}
+	}
+}
+
+// inline returns the text of a value written on its key's line: a scalar
+// or an empty collection. It is "" for other values.
+func inline(n *yamlNode) string {
+	switch {
+	case n.object && len(n.values) == 0:
+		return "{}"
+	case n.array && len(n.values) == 0:
+		return "[]"
+	case n.object || n.array:
+		return ""
+	}
+	return n.scalar
+}
+
+var (
+	plainYAML    = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_ ./-]*$`)
+	yamlKeywords = map[string]bool{
+		"true": true, "false": true, "null": true, "yes": true, "no": true,
+		"on": true, "off": true, "y": true, "n": true, "~": true,
+	}
+)
+
+// yamlString writes s unquoted when that is unambiguous and as a JSON
+// (i.e. YAML double-quoted) string otherwise.
+func yamlString(s string) string {
+	if plainYAML.MatchString(s) && !strings.HasSuffix(s, " ") && !yamlKeywords[strings.ToLower(s)] {
+		return s
+	}
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+// parseYAML decodes the YAML subset OpenAPI documents are written in into
+// the values encoding/json would produce: map[string]any, []any, string,
+// float64, bool and nil. It supports block mappings and sequences, flow
+// collections, plain and quoted scalars, literal and folded block scalars
+// and comments; anchors, aliases, tags, complex keys, multi-line plain
+// scalars and multi-document streams are rejected. JSON documents are
+// decoded with encoding/json.
+func parseYAML(data []byte) (any, error) {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+		var v any
+		if err := json.Unmarshal(trimmed, &v); err != nil {
+			return nil, err
+		}
+		return v, nil
+	}
+
+	text := strings.TrimSuffix(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		content := strings.TrimLeft(line, " \t")
+		if content != "" && strings.ContainsRune(line[:len(line)-len(content)], '\t') {
+			return nil, fmt.Errorf("openapi: yaml line %d: tabs are not allowed for indentation", i+1)
+		}
+	}
+	p := &yamlParser{lines: lines}
+	p.skipBlank()
+	if p.pos < len(p.lines) && strings.TrimSpace(stripYAMLComment(p.lines[p.pos])) == "---" {
+		p.pos++
+	}
+	p.skipBlank()
+	if p.pos == len(p.lines) {
+		return nil, nil
+	}
+	v, err := p.node(lineIndent(p.lines[p.pos]))
+	if err != nil {
+		return nil, err
+	}
+	if p.skipBlank(); p.pos < len(p.lines) {
+		return nil, p.errorf("unexpected content %q", strings.TrimSpace(p.lines[p.pos]))
+	}
+	return v, nil
+}
+
+type yamlParser struct {
+	lines []string
+	pos   int
+}
+
+func (p *yamlParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("openapi: yaml line %d: %s", p.pos+1, fmt.Sprintf(format, args...))
+}
+
+// skipBlank moves past empty and comment-only lines.
+func (p *yamlParser) skipBlank() {
+	for p.pos < len(p.lines) {
+		text := strings.TrimSpace(p.lines[p.pos])
+		if text != "" && !strings.HasPrefix(text, "#") {
+			return
+		}
+		p.pos++
+	}
+}
+
+func lineIndent(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+func isSeqItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// node parses the block node starting at the current line, which is
+// indented by indent.
+func (p *yamlParser) node(indent int) (any, error) {
+	text := strings.TrimSpace(stripYAMLComment(p.lines[p.pos]))
+	switch {
+	case text == "?" || strings.HasPrefix(text, "? "):
+		return nil, p.errorf("complex mapping keys are not supported")
+	case isSeqItem(text):
+		return p.sequence(indent)
+	case mappingColon(text) >= 0:
+		return p.mapping(indent)
+	}
+	p.pos++
+	return p.inline(text)
+}
+
+func (p *yamlParser) mapping(indent int) (any, error) {
+	m := make(map[string]any)
+	for p.skipBlank(); p.pos < len(p.lines); p.skipBlank() {
+		line := p.lines[p.pos]
+		ind := lineIndent(line)
+		text := strings.TrimSpace(stripYAMLComment(line))
+		if ind < indent || (ind == indent && isSeqItem(text)) {
+			break
+		}
+		if ind > indent {
+			return nil, p.errorf("unexpected indentation")
+		}
+		colon := mappingColon(text)
+		if colon < 0 {
+			return nil, p.errorf("expected a mapping key, got %q", text)
+		}
+		key, err := yamlKey(text[:colon])
+		if err != nil {
+			return nil, p.errorf("%v", err)
+		}
+		if _, dup := m[key]; dup {
+			return nil, p.errorf("duplicate key %q", key)
+		}
+		rest := strings.TrimSpace(text[colon+1:])
+		p.pos++
+
+		var v any
+		switch {
+		case rest == "":
+			v, err = p.child(indent, true)
+		case rest[0] == '|' || rest[0] == '>':
+			v, err = p.blockScalar(indent, rest)
+		default:
+			v, err = p.inline(rest)
+		}
+		if err != nil {
+			return nil, err
+		}
+		m[key] = v
+	}
+	return m, nil
+}
+
+func (p *yamlParser) sequence(indent int) (any, error) {
+	items := []any{}
+	for p.skipBlank(); p.pos < len(p.lines); p.skipBlank() {
+		line := p.lines[p.pos]
+		ind := lineIndent(line)
+		text := strings.TrimSpace(stripYAMLComment(line))
+		if ind < indent || (ind == indent && !isSeqItem(text)) {
+			break
+		}
+		if ind > indent {
+			return nil, p.errorf("unexpected indentation")
+		}
+
+		rest := strings.TrimLeft(line[ind+1:], " ")
+		var (
+			v   any
+			err error
+		)
+		switch trimmed := strings.TrimSpace(stripYAMLComment(rest)); {
+		case trimmed == "":
+			p.pos++
+			v, err = p.child(indent, false)
+		case trimmed[0] == '|' || trimmed[0] == '>':
+			p.pos++
+			v, err = p.blockScalar(indent, trimmed)
+		default:
+			// "- key: value" and "- - item" start a nested node on the
+			// item's line: re-read the line with the dash as indentation.
+			p.lines[p.pos] = strings.Repeat(" ", len(line)-len(rest)) + rest
+			v, err = p.node(len(line) - len(rest))
+		}
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, v)
+	}
+	return items, nil
+}
+
+// child parses the value of a key or sequence item whose content starts
+// on the next line. A mapping value may be a sequence at the key's own
+// indentation.
+func (p *yamlParser) child(indent int, inMapping bool) (any, error) {
+	p.skipBlank()
+	if p.pos == len(p.lines) {
+		return nil, nil
+	}
+	line := p.lines[p.pos]
+	ind := lineIndent(line)
+	if ind > indent || (inMapping && ind == indent && isSeqItem(strings.TrimSpace(line))) {
+		return p.node(ind)
+	}
+	return nil, nil
+}
+
+// blockScalar reads a literal (|) or folded (>) scalar with an optional
+// chomping indicator; its lines are indented more than indent.
+func (p *yamlParser) blockScalar(indent int, header string) (any, error) {
+	chomp := byte(0)
+	if len(header) > 1 {
+		chomp = header[1]
+		if (chomp != '-' && chomp != '+') || len(header) > 2 {
+			return nil, p.errorf("unsupported block scalar header %q", header)
+		}
+	}
+
+	var lines []string
+	contentIndent := -1
+	for ; p.pos < len(p.lines); p.pos++ {
+		line := p.lines[p.pos]
+		if strings.TrimSpace(line) == "" {
+			lines = append(lines, "")
+			continue
+		}
+		ind := lineIndent(line)
+		if ind <= indent {
+			break
+		}
+		if contentIndent < 0 {
+			contentIndent = ind
+		}
+		if ind < contentIndent {
+			return nil, p.errorf("block scalar is less indented than its first line")
+		}
+		lines = append(lines, line[contentIndent:])
+	}
+	// Trailing blank lines belong to the scalar only with "+".
+	trailing := 0
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+		trailing++
+	}
+
+	var s string
+	if header[0] == '|' {
+		s = strings.Join(lines, "\n")
+	} else {
+		var b strings.Builder
+		for i, line := range lines {
+			// Line breaks between text lines fold into spaces; blank
+			// lines are kept as newlines.
+			switch {
+			case line == "":
+				b.WriteString("\n")
+			case i > 0 && lines[i-1] != "":
+				b.WriteString(" ")
+			}
+			b.WriteString(line)
+		}
+		s = b.String()
+	}
+	switch {
+	case len(lines) == 0:
+	case chomp == '-':
+	case chomp == '+':
+		s += strings.Repeat("\n", trailing+1)
+	default:
+		s += "\n"
+	}
+	return s, nil
+}
+
+// inline parses a value written on one line. Flow collections may continue
+// on the following lines.
+func (p *yamlParser) inline(text string) (any, error) {
+	if text[0] == '[' || text[0] == '{' {
+		for !flowBalanced(text) && p.pos < len(p.lines) {
+			text += " " + strings.TrimSpace(stripYAMLComment(p.lines[p.pos]))
+			p.pos++
+		}
+		f := &flowParser{s: text}
+		v, err := f.value()
+		if err == nil {
+			if f.skipSpace(); f.i < len(f.s) {
+				err = fmt.Errorf("unexpected %q after flow collection", f.s[f.i:])
+			}
+		}
+		if err != nil {
+			return nil, p.errorf("%v", err)
+		}
+		return v, nil
+	}
+	if mappingColon(text) >= 0 {
+		// "x: key: value" is a mapping where only a scalar may follow.
+		return nil, p.errorf("mapping values are not allowed here: %q", text)
+	}
+	v, err := yamlScalar(text)
+	if err != nil {
+		return nil, p.errorf("%v", err)
+	}
+	return v, nil
+}
+
+// mappingColon returns the index of the colon ending a mapping key in
+// text, or -1. The colon must be followed by a space or end the line, and
+// not be inside quotes or a flow collection.
+func mappingColon(text string) int {
+	if text == "" || text[0] == '[' || text[0] == '{' {
+		return -1
+	}
+	if text[0] == '"' || text[0] == '\'' {
+		end := quotedEnd(text)
+		if end < 0 {
+			return -1
+		}
+		if rest := text[end+1:]; strings.HasPrefix(strings.TrimLeft(rest, " "), ":") {
+			colon := end + 1 + strings.IndexByte(rest, ':')
+			if colon+1 == len(text) || text[colon+1] == ' ' {
+				return colon
+			}
+		}
+		return -1
+	}
+	for i := 0; i < len(text); i++ {
+		if text[i] == ':' && (i+1 == len(text) || text[i+1] == ' ') {
+			return i
+		}
+	}
+	return -1
+}
+
+// quotedEnd returns the index of the quote closing the scalar that starts
+// at s[0], or -1.
+func quotedEnd(s string) int {
+	q := s[0]
+	for i := 1; i < len(s); i++ {
+		switch {
+		case q == '"' && s[i] == '\\':
+			i++
+		case s[i] == q && q == '\'' && i+1 < len(s) && s[i+1] == '\'':
+			i++
+		case s[i] == q:
+			return i
+		}
+	}
+	return -1
+}
+
+// stripYAMLComment removes a trailing "# comment" outside quotes.
+func stripYAMLComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote == '"' && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			if i == 0 || strings.ContainsRune(" \t[{,:-", rune(line[i-1])) {
+				quote = c
+			}
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+func flowBalanced(s string) bool {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote == '"' && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[' || c == '{':
+			depth++
+		case c == ']' || c == '}':
+			depth--
+		}
+	}
+	return depth <= 0
+}
+
+func yamlKey(text string) (string, error) {
+	text = strings.TrimSpace(text)
+	if text != "" && (text[0] == '"' || text[0] == '\'') {
+		return unquoteYAML(text)
+	}
+	if strings.HasPrefix(text, "? ") || strings.HasPrefix(text, "&") || strings.HasPrefix(text, "*") {
+		return "", fmt.Errorf("unsupported key %q", text)
+	}
+	return text, nil
+}
+
+var (
+	yamlInt   = regexp.MustCompile(`^[-+]?(0|[1-9][0-9]*)$`)
+	yamlFloat = regexp.MustCompile(`^[-+]?(\.[0-9]+|[0-9]+(\.[0-9]*)?)([eE][-+]?[0-9]+)?$`)
+)
+
+// yamlScalar resolves a plain or quoted scalar with the YAML 1.2 core
+// schema.
+func yamlScalar(text string) (any, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, nil
+	}
+	switch text[0] {
+	case '"', '\'':
+		if quotedEnd(text) != len(text)-1 {
+			return nil, fmt.Errorf("unterminated or trailing characters in %q", text)
+		}
+		return unquoteYAML(text)
+	case '&', '*', '!', '%', '@', '`':
+		return nil, fmt.Errorf("unsupported YAML construct %q", text)
+	}
+
+	switch text {
+	case "null", "Null", "NULL", "~":
+		return nil, nil
+	case "true", "True", "TRUE":
+		return true, nil
+	case "false", "False", "FALSE":
+		return false, nil
+	case ".inf", ".Inf", ".INF", "+.inf":
+		return math.Inf(1), nil
+	case "-.inf", "-.Inf", "-.INF":
+		return math.Inf(-1), nil
+	}
+	if yamlInt.MatchString(text) || yamlFloat.MatchString(text) {
+		if f, err := strconv.ParseFloat(text, 64); err == nil {
+			return f, nil
+		}
+	}
+	return text, nil
+}
+
+func unquoteYAML(text string) (string, error) {
+	if text[0] == '\'' {
+		return strings.ReplaceAll(text[1:len(text)-1], "''", "'"), nil
+	}
+	s, err := strconv.Unquote(strings.ReplaceAll(text, `\/`, "/"))
+	if err != nil {
+		return "", fmt.Errorf("invalid double-quoted string %s", text)
+	}
+	return s, nil
+}
+
+// flowParser parses flow collections: [a, "b", {c: d}].
+type flowParser struct {
+	s string
+	i int
+}
+
+func (f *flowParser) skipSpace() {
+	for f.i < len(f.s) && (f.s[f.i] == ' ' || f.s[f.i] == '\t') {
+		f.i++
+	}
+}
+
+func (f *flowParser) value() (any, error) {
+	f.skipSpace()
+	if f.i == len(f.s) {
+		return nil, fmt.Errorf("unexpected end of flow collection")
+	}
+	switch f.s[f.i] {
+	case '[':
+		f.i++
+		items := []any{}
+		for {
+			if f.skipSpace(); f.i < len(f.s) && f.s[f.i] == ']' {
+				f.i++
+				return items, nil
+			}
+			v, err := f.value()
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, v)
+			if err := f.separator(']'); err != nil {
+				return nil, err
+			}
+		}
+	case '{':
+		f.i++
+		m := make(map[string]any)
+		for {
+			if f.skipSpace(); f.i < len(f.s) && f.s[f.i] == '}' {
+				f.i++
+				return m, nil
+			}
+			k, err := f.scalar(true)
+			if err != nil {
+				return nil, err
+			}
+			key, ok := k.(string)
+			if !ok {
+				key = fmt.Sprint(k)
+			}
+			if f.skipSpace(); f.i == len(f.s) || f.s[f.i] != ':' {
+				return nil, fmt.Errorf("expected ':' after flow mapping key %q", key)
+			}
+			f.i++
+			v, err := f.value()
+			if err != nil {
+				return nil, err
+			}
+			m[key] = v
+			if err := f.separator('}'); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return f.scalar(false)
+}
+
+// separator consumes a comma, or leaves the closing delimiter in place.
+func (f *flowParser) separator(closing byte) error {
+	f.skipSpace()
+	switch {
+	case f.i < len(f.s) && f.s[f.i] == ',':
+		f.i++
+		return nil
+	case f.i < len(f.s) && f.s[f.i] == closing:
+		return nil
+	}
+	return fmt.Errorf("expected ',' or %q in flow collection", closing)
+}
+
+// scalar reads a scalar up to the next flow indicator. Keys also stop at
+// ':'.
+func (f *flowParser) scalar(key bool) (any, error) {
+	f.skipSpace()
+	start := f.i
+	if f.i < len(f.s) && (f.s[f.i] == '"' || f.s[f.i] == '\'') {
+		end := quotedEnd(f.s[f.i:])
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated string in flow collection")
+		}
+		f.i += end + 1
+		return unquoteYAML(f.s[start:f.i])
+	}
+	for f.i < len(f.s) && !strings.ContainsRune(",[]{}", rune(f.s[f.i])) &&
+		!(key && f.s[f.i] == ':' && (f.i+1 == len(f.s) || f.s[f.i+1] == ' ')) {
+		f.i++
+	}
+	return yamlScalar(f.s[start:f.i])
+}
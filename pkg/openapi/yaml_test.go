@@ -0,0 +1,130 @@
+package openapi
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestParseYAML(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string // JSON encoding of the result
+	}{
+		{"empty document", "", `null`},
+		{"document start", "---\na: 1\n", `{"a":1}`},
+		{"block mapping", "a: 1\nb:\n  c: x\n  d: true\n", `{"a":1,"b":{"c":"x","d":true}}`},
+		{"block sequence", "- a\n- 2\n- ~\n", `["a",2,null]`},
+		{"sequence under key at same indent", "a:\n- x\n- y\nb: 1\n", `{"a":["x","y"],"b":1}`},
+		{"compact mapping in sequence", "- name: id\n  in: path\n-   name: q\n    in: query\n",
+			`[{"in":"path","name":"id"},{"in":"query","name":"q"}]`},
+		{"nested sequences", "- - 1\n  - 2\n- - 3\n", `[[1,2],[3]]`},
+		{"empty value is null", "a:\nb: 1\n", `{"a":null,"b":1}`},
+		{"flow sequence", `a: [x, "y, z", 3, {k: v}]`, `{"a":["x","y, z",3,{"k":"v"}]}`},
+		{"flow mapping", `a: {type: integer, minimum: 1, enum: [1, 2]}`, `{"a":{"enum":[1,2],"minimum":1,"type":"integer"}}`},
+		{"empty flow collections", "a: {}\nb: []\n", `{"a":{},"b":[]}`},
+		{"multi-line flow", "a: [x,\n  y]\nb: 1\n", `{"a":["x","y"],"b":1}`},
+		{"literal block", "a: |\n  one\n  two\n\nb: 1\n", `{"a":"one\ntwo\n","b":1}`},
+		{"literal strip", "a: |-\n  one\n  two\n", `{"a":"one\ntwo"}`},
+		{"literal keep", "a: |+\n  one\n\n", `{"a":"one\n\n"}`},
+		{"folded block", "a: >\n  one\n  two\n\n  three\n", `{"a":"one two\nthree\n"}`},
+		{"block scalar in sequence", "- |\n  text\n", `["text\n"]`},
+		{"double quotes", `a: "x: \"y\" # z\n"`, `{"a":"x: \"y\" # z\n"}`},
+		{"single quotes", `a: 'it''s: here'`, `{"a":"it's: here"}`},
+		{"quoted keys", "'200': ok\n\"/a/{id}\": b\n", `{"/a/{id}":"b","200":"ok"}`},
+		{"comments", "# head\na: 1 # trailing\n  # indented\nb: x#not-a-comment\n", `{"a":1,"b":"x#not-a-comment"}`},
+		{"core schema scalars", "a: [true, False, null, ~, 1.5, -2, 0x1F, 1e3, yes, 3.0.3]",
+			`{"a":[true,false,null,null,1.5,-2,"0x1F",1000,"yes","3.0.3"]}`},
+		{"colon inside plain scalar", "a: http://x/y\nb: 'k: v'\n", `{"a":"http://x/y","b":"k: v"}`},
+		{"crlf line endings", "a: 1\r\nb: 2\r\n", `{"a":1,"b":2}`},
+		{"JSON document", `{"a": [1, {"b": null}]}`, `{"a":[1,{"b":null}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := parseYAML([]byte(tt.in))
+			if err != nil {
+				t.Fatalf("parseYAML: %v", err)
+			}
+			if got := encodeForTest(v); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseYAMLInfinity(t *testing.T) {
+	v, err := parseYAML([]byte("a: .inf\nb: -.Inf\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := v.(map[string]any)
+	if !math.IsInf(m["a"].(float64), 1) || !math.IsInf(m["b"].(float64), -1) {
+		t.Errorf("got %v", m)
+	}
+}
+
+func TestParseYAMLRejects(t *testing.T) {
+	tests := []struct {
+		name, in, err string
+	}{
+		{"nested mapping on one line", "x: key: value\n", "mapping values are not allowed"},
+		{"nested mapping in sequence item", "- a: b: c\n", "mapping values are not allowed"},
+		{"anchor", "a: &x 1\n", "unsupported"},
+		{"alias", "a: *x\n", "unsupported"},
+		{"anchor on block node", "a: &x\n  b: 1\n", "unsupported"},
+		{"tag", "a: !!str 1\n", "unsupported"},
+		{"complex key", "? a\n: b\n", "complex mapping keys"},
+		{"multi-document stream", "a: 1\n---\nb: 2\n", "expected a mapping key"},
+		{"multi-line plain scalar", "a: one\n  two\n", "unexpected indentation"},
+		{"tab indentation", "a:\n\tb: 1\n", "tabs"},
+		{"duplicate key", "a: 1\na: 2\n", "duplicate key"},
+		{"bad indentation", "a:\n    b: 1\n  c: 2\n", "unexpected indentation"},
+		{"unterminated flow", "a: [1, 2\n", "flow"},
+		{"unterminated quote", `a: "x`, "unterminated"},
+		{"trailing text after quote", `a: "x" y`, "unterminated or trailing"},
+		{"bad block scalar header", "a: |2\n  x\n", "block scalar header"},
+		{"invalid JSON", `{"a": }`, "invalid character"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := parseYAML([]byte(tt.in))
+			if err == nil {
+				t.Fatalf("parsed as %s, want an error", encodeForTest(v))
+			}
+			if !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("error %q does not mention %q", err, tt.err)
+			}
+		})
+	}
+}
+
+// TestYAMLRoundTrip checks that jsonToYAML output parses back to the same
+// value, including strings that need quoting.
+func TestYAMLRoundTrip(t *testing.T) {
+	in := `{"a":{"$ref":"#/x","b":["", "true", "1.0", "x: y", " lead", "trail ", "# c", "- d", "line\nbreak", null, false, 2.5]},"e":{},"f":[],"g":[{"h":1,"i":[{"j":"k"}]}]}`
+	y, err := jsonToYAML([]byte(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := parseYAML(y)
+	if err != nil {
+		t.Fatalf("parseYAML: %v\n%s", err, y)
+	}
+	var want any
+	if err := json.Unmarshal([]byte(in), &want); err != nil {
+		t.Fatal(err)
+	}
+	if got, w := encodeForTest(v), encodeForTest(want); got != w {
+		t.Errorf("round trip changed the value:\n got %s\nwant %s\nyaml:\n%s", got, w, y)
+	}
+}
+
+func encodeForTest(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "error: " + err.Error()
+	}
+	return string(b)
+}
@@ -23,6 +23,36 @@ func Error(ctx *router.Context, status int, code, message string) {
 	})
 }
 
+// ValidationError sends the response for an error returned by
+// Context.Bind or Context.BindJSON. Validation failures produce 422
+// Unprocessable Entity with one entry per failing field:
+//
+//	{"error": {"code": "validation_failed", "message": "...",
+//	           "details": [{"field": "age", "code": "min", "param": "1", "message": "age must be at least 1"}]}}
+//
+// A value that could not be converted produces the same shape with code
+// "invalid" for that field. A body that could not be decoded uses the
+// status and kind of its *router.JSONError (e.g. 413 "body_too_large");
+// any other error is a 400 "invalid_request".
+func ValidationError(ctx *router.Context, err error) {
+	he := router.AsHTTPError(err)
+	if he.Status == http.StatusInternalServerError {
+		Error(ctx, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	if he.Details != nil {
+		ctx.JSON(he.Status, map[string]any{
+			"error": map[string]any{
+				"code":    he.Code,
+				"message": he.Message,
+				"details": he.Details,
+			},
+		})
+		return
+	}
+	Error(ctx, he.Status, he.Code, he.Message)
+}
+
 // Success sends a success response with the given status code and data.
 func Success(ctx *router.Context, status int, data any) {
 	ctx.JSON(status, map[string]any{
@@ -0,0 +1,72 @@
+package router
+
+import (
+	"net/http"
+	"strings"
+)
+
+// mountParam is the catch-all that receives the path below a Mount prefix.
+const mountParam = "mountpath"
+
+// WrapH adapts a standard http.Handler to a Handler.
+func WrapH(h http.Handler) Handler {
+	return func(ctx *Context) {
+		h.ServeHTTP(ctx.ResponseWriter(), ctx.Request())
+	}
+}
+
+// WrapF adapts a standard http.HandlerFunc to a Handler.
+func WrapF(f http.HandlerFunc) Handler {
+	return WrapH(f)
+}
+
+// WrapM adapts standard net/http middleware to a Middleware. A request or
+// ResponseWriter replaced by mw is visible to the rest of the chain.
+func WrapM(mw func(http.Handler) http.Handler) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx *Context) {
+			req, res := ctx.req, ctx.res
+			mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				ctx.req, ctx.res = r, w
+				next(ctx)
+			})).ServeHTTP(ctx.res, ctx.req)
+			ctx.req, ctx.res = req, res
+		}
+	}
+}
+
+// Mount serves h for every path below prefix, with the prefix stripped
+// from the request URL as http.StripPrefix would. The group's middleware
+// (logging, recovery, request ID, ...) runs in front of h. Mount registers
+// GET, POST, PUT, PATCH, DELETE, HEAD and OPTIONS.
+func (r *Router) Mount(prefix string, h http.Handler, mw ...Middleware) []*Route {
+	prefix = strings.TrimSuffix(prefix, "/")
+	full := r.prefix + prefix
+	if !strings.HasPrefix(full, "/") {
+		full = "/" + full
+	}
+	return r.Any(prefix+"/*"+mountParam, stripPrefix(full, h), mw...)
+}
+
+// stripPrefix serves h with prefix removed from the request path, keeping
+// a leading slash so "/prefix" maps to "/".
+func stripPrefix(prefix string, h http.Handler) Handler {
+	return func(ctx *Context) {
+		req := ctx.Request()
+
+		p := "/" + strings.TrimPrefix(strings.TrimPrefix(req.URL.Path, prefix), "/")
+		rp := ""
+		if req.URL.RawPath != "" {
+			rp = "/" + strings.TrimPrefix(strings.TrimPrefix(req.URL.RawPath, prefix), "/")
+		}
+
+		r2 := new(http.Request)
+		*r2 = *req
+		u := *req.URL
+		u.Path = p
+		u.RawPath = rp
+		r2.URL = &u
+
+		h.ServeHTTP(ctx.ResponseWriter(), r2)
+	}
+}
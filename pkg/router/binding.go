@@ -0,0 +1,317 @@
+package router
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alejandrombjs/go-bastion-lib/pkg/validate"
+)
+
+// bindSources are the struct tags read by Bind, in the order they are
+// applied; later sources overwrite earlier ones.
+var bindSources = []string{"form", "query", "header", "cookie", "path"}
+
+// BindError reports a value that could not be bound to a struct field.
+type BindError struct {
+	// Field is the Go field path, e.g. "Filter.Since".
+	Field string
+	// Source is the tag the value came from: "path", "query", "header",
+	// "form" or "cookie".
+	Source string
+	// Key is the name in the source, e.g. the query parameter name.
+	Key string
+	// Value is the raw value that failed to convert.
+	Value string
+	Err   error
+}
+
+func (e *BindError) Error() string {
+	return fmt.Sprintf("bind: %s %q (field %s): invalid value %q: %v", e.Source, e.Key, e.Field, e.Value, e.Err)
+}
+
+func (e *BindError) Unwrap() error {
+	return e.Err
+}
+
+// Bind fills the struct pointed to by dest from the request. A body that
+// is not a form is decoded first as JSON, whatever its Content-Type
+// unless the route's JSONOptions set RequireContentType, according to the
+// json tags and JSONOptions (an empty body is allowed). Then fields tagged
+// form:"name", query:"name", header:"Name", cookie:"name" and path:"name"
+// are set from the form body, query string, headers, cookies and path
+// parameters, in that order:
+//
+//	type listRequest struct {
+//		TenantID string        `header:"X-Tenant"`
+//		Page     int           `query:"page"`
+//		Tags     []string      `query:"tag"`
+//		Since    *time.Time    `query:"since"`
+//		Timeout  time.Duration `query:"timeout"`
+//	}
+//
+// Besides strings, bools, integers and floats, fields may be
+// time.Time (RFC 3339 or 2006-01-02), time.Duration, pointers, slices
+// (one element per repeated value) or implement encoding.TextUnmarshaler.
+// Fields whose key is absent from the request are left untouched. Nested
+// and embedded structs are bound recursively. Conversion failures are
+// returned as a *BindError naming the field, body errors as a *JSONError.
+//
+// Once bound, dest is checked against its validate tags (see package
+// validate); failures are returned as validate.Errors.
+func (c *Context) Bind(dest any) error {
+	if err := c.bind(dest); err != nil {
+		return err
+	}
+	return validate.Struct(dest)
+}
+
+func (c *Context) bind(dest any) error {
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("bind: destination must be a non-nil pointer to a struct, got %T", dest)
+	}
+
+	if !isFormRequest(c.req) && (isJSONRequest(c.req) || hasBody(c.req)) {
+		if err := c.decodeJSON(dest, true); err != nil {
+			return err
+		}
+	}
+
+	fields := bindFieldsOf(rv.Elem().Type())
+	if len(fields) == 0 {
+		return nil
+	}
+	if isFormRequest(c.req) {
+		if err := c.req.ParseMultipartForm(32 << 20); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+			return fmt.Errorf("bind: parsing form: %w", err)
+		}
+	}
+
+	var query map[string][]string
+	for _, source := range bindSources {
+		for _, f := range fields {
+			if f.source != source {
+				continue
+			}
+			var values []string
+			switch source {
+			case "form":
+				values = c.req.PostForm[f.key]
+			case "query":
+				if query == nil {
+					query = c.req.URL.Query()
+				}
+				values = query[f.key]
+			case "header":
+				values = c.req.Header.Values(f.key)
+			case "cookie":
+				for _, cookie := range c.req.Cookies() {
+					if cookie.Name == f.key {
+						values = append(values, cookie.Value)
+					}
+				}
+			case "path":
+				for _, p := range c.params {
+					if p.key == f.key {
+						values = append(values, p.value)
+					}
+				}
+			}
+			if len(values) == 0 {
+				continue
+			}
+			if err := setField(rv.Elem().FieldByIndex(f.index), values); err != nil {
+				return &BindError{Field: f.name, Source: source, Key: f.key, Value: strings.Join(values, ","), Err: err}
+			}
+		}
+	}
+	return nil
+}
+
+// hasBody reports whether req may carry a body: one with a known non-zero
+// length, or a chunked one.
+func hasBody(req *http.Request) bool {
+	return req.Body != nil && req.Body != http.NoBody && req.ContentLength != 0
+}
+
+func isFormRequest(req *http.Request) bool {
+	mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	return mediaType == "application/x-www-form-urlencoded" || mediaType == "multipart/form-data"
+}
+
+// bindField is a struct field with a binding tag.
+type bindField struct {
+	name   string // Go field path, for errors
+	index  []int
+	source string
+	key    string
+}
+
+// bindFields caches the tagged fields per struct type.
+var bindFields sync.Map // reflect.Type -> []bindField
+
+func bindFieldsOf(t reflect.Type) []bindField {
+	if v, ok := bindFields.Load(t); ok {
+		return v.([]bindField)
+	}
+	fields := collectBindFields(t, nil, "")
+	bindFields.Store(t, fields)
+	return fields
+}
+
+func collectBindFields(t reflect.Type, index []int, prefix string) []bindField {
+	var fields []bindField
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() && !sf.Anonymous {
+			continue
+		}
+		idx := append(append([]int(nil), index...), i)
+		name := prefix + sf.Name
+
+		tagged := false
+		for _, source := range bindSources {
+			key, ok := sf.Tag.Lookup(source)
+			if !ok || key == "-" {
+				continue
+			}
+			tagged = true
+			if key == "" {
+				key = sf.Name
+			}
+			fields = append(fields, bindField{name: name, index: idx, source: source, key: key})
+		}
+		if tagged {
+			continue
+		}
+
+		if sf.Type.Kind() == reflect.Struct && !isScalarStruct(sf.Type) {
+			nested := prefix
+			if !sf.Anonymous {
+				nested = name + "."
+			}
+			fields = append(fields, collectBindFields(sf.Type, idx, nested)...)
+		}
+	}
+	return fields
+}
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	timeType            = reflect.TypeOf(time.Time{})
+	durationType        = reflect.TypeOf(time.Duration(0))
+)
+
+// isScalarStruct reports whether a struct type is bound from a single
+// value rather than field by field.
+func isScalarStruct(t reflect.Type) bool {
+	return t == timeType || reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+// setField converts values into v. Slices take every value; other kinds
+// take the first.
+func setField(v reflect.Value, values []string) error {
+	if v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8 {
+		if !v.Addr().Type().Implements(textUnmarshalerType) {
+			slice := reflect.MakeSlice(v.Type(), len(values), len(values))
+			for i, s := range values {
+				if err := setValue(slice.Index(i), s); err != nil {
+					return err
+				}
+			}
+			v.Set(slice)
+			return nil
+		}
+	}
+	return setValue(v, values[0])
+}
+
+func setValue(v reflect.Value, s string) error {
+	if v.Kind() == reflect.Pointer {
+		elem := reflect.New(v.Type().Elem())
+		if err := setValue(elem.Elem(), s); err != nil {
+			return err
+		}
+		v.Set(elem)
+		return nil
+	}
+
+	switch v.Type() {
+	case timeType:
+		t, err := parseTime(s)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	case durationType:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(s))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			v.SetBytes([]byte(s))
+			return nil
+		}
+		fallthrough
+	default:
+		return fmt.Errorf("unsupported field type %s", v.Type())
+	}
+	return nil
+}
+
+// parseTime accepts RFC 3339 timestamps and plain dates.
+func parseTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("cannot parse %q as RFC 3339 time or date", s)
+	}
+	return t, nil
+}
@@ -0,0 +1,93 @@
+package router
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// constraint reports whether a path segment is acceptable for a :param.
+type constraint func(segment string) bool
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// builtinConstraints are the named constraints usable as :name<kind>.
+// Anything else between the angle brackets is compiled as a regular
+// expression that must match the whole segment.
+var builtinConstraints = map[string]constraint{
+	// int and uint check the digits by hand first: strconv errors allocate,
+	// and rejecting a segment must stay allocation-free.
+	"int": func(s string) bool {
+		if !isDigits(strings.TrimPrefix(strings.TrimPrefix(s, "-"), "+")) {
+			return false
+		}
+		_, err := strconv.ParseInt(s, 10, 64)
+		return err == nil
+	},
+	"uint": func(s string) bool {
+		if !isDigits(s) {
+			return false
+		}
+		_, err := strconv.ParseUint(s, 10, 64)
+		return err == nil
+	},
+	"alpha": func(s string) bool {
+		if s == "" {
+			return false
+		}
+		for _, c := range s {
+			if (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') {
+				return false
+			}
+		}
+		return true
+	},
+	"uuid": uuidPattern.MatchString,
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// parseParamSegment splits a ":name<constraint>" segment into its name and
+// compiled constraint. The constraint is nil when the segment has none.
+func parseParamSegment(segment, path string) (string, constraint) {
+	name := segment[1:]
+	open := strings.IndexByte(name, '<')
+	if open < 0 {
+		if name == "" {
+			panic(fmt.Sprintf("router: parameter in %q must be named, e.g. :id", path))
+		}
+		return name, nil
+	}
+	if !strings.HasSuffix(name, ">") {
+		panic(fmt.Sprintf("router: unterminated constraint in segment %q of %q", segment, path))
+	}
+
+	expr := name[open+1 : len(name)-1]
+	name = name[:open]
+	if name == "" {
+		panic(fmt.Sprintf("router: parameter in %q must be named, e.g. :id<int>", path))
+	}
+	if expr == "" {
+		panic(fmt.Sprintf("router: empty constraint in segment %q of %q", segment, path))
+	}
+	if c, ok := builtinConstraints[expr]; ok {
+		return name, c
+	}
+
+	re, err := regexp.Compile("^(?:" + expr + ")$")
+	if err != nil {
+		panic(fmt.Sprintf("router: invalid constraint %q in %q: %v", expr, path, err))
+	}
+	return name, re.MatchString
+}
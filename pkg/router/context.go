@@ -1,113 +1,251 @@
 package router
 
 import (
-    "encoding/json"
-    "net/http"
-    "sync"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
+// Context implements context.Context, so it can be passed directly to
+// database/sql, HTTP clients and anything else that takes one:
+//
+//	rows, err := db.QueryContext(ctx, "SELECT ...")
+//
+// A Context whose Deadline, Done, Err or Value method is called may be
+// kept by what it was passed to, so the router stops recycling it for
+// later requests. Wrappers that call none of these while the request runs,
+// such as context.WithoutCancel(ctx) and context.WithValue(ctx, ...), go
+// unnoticed: derive contexts that outlive the request from
+// ctx.Request().Context() instead.
+var _ context.Context = (*Context)(nil)
+
 type Context struct {
-    req        *http.Request
-    res        http.ResponseWriter
-    params     map[string]string
-    store      map[string]any
-    mu         sync.RWMutex
-    statusCode int
-    written    bool
+	// router is the root router serving the request; nil for Contexts
+	// made with NewContext.
+	router *Router
+	req    *http.Request
+	// res is the writer handlers write to: &resp, or a writer that
+	// net/http middleware adapted with WrapM put in front of it.
+	res    http.ResponseWriter
+	resp   Response
+	params []pathParam
+	store  map[string]any
+	mu     sync.RWMutex
+	route  *Route
+	// escaped is set once the Context is used as a context.Context; the
+	// router then leaves it out of its pool.
+	escaped atomic.Bool
 }
 
 func NewContext(w http.ResponseWriter, r *http.Request) *Context {
-    return &Context{
-        req:        r,
-        res:        w,
-        params:     make(map[string]string),
-        store:      make(map[string]any),
-        statusCode: http.StatusOK,
-    }
-}
-// StatusCode returns the HTTP status code that was written for this request.
-// By default it's http.StatusOK unless changed via c.Status(...) or helpers.
+	c := &Context{}
+	c.reset(w, r)
+	return c
+}
+
+// reset prepares a pooled Context for a new request, keeping the params
+// slice and store map allocations.
+func (c *Context) reset(w http.ResponseWriter, r *http.Request) {
+	c.req = r
+	c.resp.reset(w)
+	c.res = &c.resp
+	c.params = c.params[:0]
+	for k := range c.store {
+		delete(c.store, k)
+	}
+	c.route = nil
+}
+
+// StatusCode returns the HTTP status code that was written for this request,
+// however it was written. By default it's http.StatusOK.
 func (c *Context) StatusCode() int {
-    return c.statusCode
+	return c.resp.Status()
+}
+
+// Response returns the writer that records the status, size and timing of
+// the response.
+func (c *Context) Response() *Response {
+	return &c.resp
 }
 func (c *Context) Request() *http.Request {
-    return c.req
+	return c.req
 }
 
 func (c *Context) ResponseWriter() http.ResponseWriter {
-    return c.res
+	return c.res
+}
+
+// SetRequest replaces the request seen by the rest of the chain, e.g. after
+// r.WithContext. Values stored with Set are kept.
+func (c *Context) SetRequest(r *http.Request) {
+	c.req = r
+}
+
+// --------- context.Context ---------
+
+// Deadline returns the deadline of the request context.
+func (c *Context) Deadline() (deadline time.Time, ok bool) {
+	c.escaped.Store(true)
+	return c.req.Context().Deadline()
+}
+
+// Done returns the request context's Done channel, closed when the client
+// goes away or the server shuts down.
+func (c *Context) Done() <-chan struct{} {
+	c.escaped.Store(true)
+	return c.req.Context().Done()
+}
+
+// Err returns the request context's error once Done is closed.
+func (c *Context) Err() error {
+	c.escaped.Store(true)
+	return c.req.Context().Err()
+}
+
+// Value returns the value stored with Set when key is a string holding
+// one, and otherwise the request context's value for key.
+func (c *Context) Value(key any) any {
+	c.escaped.Store(true)
+	if k, ok := key.(string); ok {
+		if v, ok := c.Get(k); ok {
+			return v
+		}
+	}
+	return c.req.Context().Value(key)
+}
+
+// WithValue attaches a value to the request context, so that it is seen by
+// Value, by ctx.Request().Context() and by net/http handlers further down
+// the chain.
+func (c *Context) WithValue(key, value any) {
+	c.req = c.req.WithContext(context.WithValue(c.req.Context(), key, value))
 }
 
 // --------- ROUTE PARAMS ---------
 
 func (c *Context) Param(name string) string {
-    return c.params[name]
+	for _, p := range c.params {
+		if p.key == name {
+			return p.value
+		}
+	}
+	return ""
+}
+
+// ParamInt returns the named path parameter parsed as an int.
+// Pair it with a :name<int> constraint to guarantee it parses.
+func (c *Context) ParamInt(name string) (int, error) {
+	return strconv.Atoi(c.Param(name))
+}
+
+// ParamInt64 returns the named path parameter parsed as an int64.
+func (c *Context) ParamInt64(name string) (int64, error) {
+	return strconv.ParseInt(c.Param(name), 10, 64)
+}
+
+// --------- ROUTE INFO ---------
+
+// RoutePattern returns the pattern of the matched route, e.g. "/users/:id",
+// or "" when no route matched.
+func (c *Context) RoutePattern() string {
+	if c.route == nil {
+		return ""
+	}
+	return c.route.path
+}
+
+// RouteName returns the name of the matched route, if it has one.
+func (c *Context) RouteName() string {
+	if c.route == nil {
+		return ""
+	}
+	c.route.group.root().mu.RLock()
+	defer c.route.group.root().mu.RUnlock()
+	return c.route.name
+}
+
+// RouteMeta returns a metadata value attached to the matched route with
+// Route.Meta.
+func (c *Context) RouteMeta(key string) (any, bool) {
+	if c.route == nil {
+		return nil, false
+	}
+	return c.route.value(key)
 }
 
 // --------- QUERY / FORM ---------
 
 func (c *Context) Query(name string) string {
-    return c.req.URL.Query().Get(name)
+	return c.req.URL.Query().Get(name)
 }
 
 func (c *Context) FormValue(name string) string {
-    return c.req.FormValue(name)
+	return c.req.FormValue(name)
 }
 
 // --------- JSON BINDING ---------
 
+// BindJSON decodes the JSON request body into dest, applying the route's
+// JSONOptions (size limit, unknown fields, Content-Type, trailing data).
+// Decoding failures are returned as a *JSONError whose Status is the
+// response status to use.
 func (c *Context) BindJSON(dest any) error {
-    return json.NewDecoder(c.req.Body).Decode(dest)
+	return c.decodeJSON(dest, false)
 }
 
 // --------- RESPONSE HELPERS ---------
 
 func (c *Context) Status(code int) {
-    if c.written {
-        return
-    }
-    c.statusCode = code
-    c.res.WriteHeader(code)
-    c.written = true
+	if c.resp.Written() {
+		return
+	}
+	c.res.WriteHeader(code)
 }
 
 func (c *Context) JSON(status int, value any) {
-    c.res.Header().Set("Content-Type", "application/json")
-    c.Status(status)
-    if err := json.NewEncoder(c.res).Encode(value); err != nil {
-        http.Error(c.res, err.Error(), http.StatusInternalServerError)
-    }
+	c.res.Header().Set("Content-Type", "application/json")
+	c.Status(status)
+	if err := json.NewEncoder(c.res).Encode(value); err != nil {
+		http.Error(c.res, err.Error(), http.StatusInternalServerError)
+	}
 }
 
 // --------- CONTEXT STORE ---------
 
 func (c *Context) Set(key string, value any) {
-    c.mu.Lock()
-    defer c.mu.Unlock()
-    c.store[key] = value
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.store == nil {
+		c.store = make(map[string]any)
+	}
+	c.store[key] = value
 }
 
 func (c *Context) Get(key string) (any, bool) {
-    c.mu.RLock()
-    defer c.mu.RUnlock()
-    v, ok := c.store[key]
-    return v, ok
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	v, ok := c.store[key]
+	return v, ok
 }
 
 func (c *Context) GetString(key string) (string, bool) {
-    v, ok := c.Get(key)
-    if !ok {
-        return "", false
-    }
-    s, ok := v.(string)
-    return s, ok
+	v, ok := c.Get(key)
+	if !ok {
+		return "", false
+	}
+	s, ok := v.(string)
+	return s, ok
 }
 
 func (c *Context) GetInt(key string) (int, bool) {
-    v, ok := c.Get(key)
-    if !ok {
-        return 0, false
-    }
-    i, ok := v.(int)
-    return i, ok
+	v, ok := c.Get(key)
+	if !ok {
+		return 0, false
+	}
+	i, ok := v.(int)
+	return i, ok
 }
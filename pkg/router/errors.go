@@ -0,0 +1,227 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+
+	"github.com/alejandrombjs/go-bastion-lib/pkg/validate"
+)
+
+// HandlerE is a handler that returns an error instead of writing it. E
+// adapts it for the route methods:
+//
+//	r.GET("/users/:id", router.E(func(ctx *router.Context) error {
+//		user, err := store.Find(ctx, ctx.Param("id"))
+//		if errors.Is(err, store.ErrNotFound) {
+//			return router.NewHTTPError(http.StatusNotFound, "user_not_found", "User not found")
+//		}
+//		if err != nil {
+//			return err
+//		}
+//		ctx.JSON(http.StatusOK, user)
+//		return nil
+//	}))
+//
+// A non-nil error is passed to the router's ErrorHandler.
+type HandlerE func(ctx *Context) error
+
+// HTTPError is an error with the response it should produce. Err, the
+// underlying cause, is logged; the standard ErrorHandler only shows it to
+// clients in development.
+type HTTPError struct {
+	Status  int
+	Code    string
+	Message string
+	Details any
+	Err     error
+}
+
+// NewHTTPError returns an HTTPError for status with a machine-readable
+// code and a client-facing message.
+func NewHTTPError(status int, code, message string) *HTTPError {
+	return &HTTPError{Status: status, Code: code, Message: message}
+}
+
+// WithDetails returns a copy of e carrying details, e.g. the offending
+// fields.
+func (e *HTTPError) WithDetails(details any) *HTTPError {
+	cp := *e
+	cp.Details = details
+	return &cp
+}
+
+// Wrap returns a copy of e caused by err.
+func (e *HTTPError) Wrap(err error) *HTTPError {
+	cp := *e
+	cp.Err = err
+	return &cp
+}
+
+func (e *HTTPError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%d %s: %s: %v", e.Status, e.Code, e.Message, e.Err)
+	}
+	return fmt.Sprintf("%d %s: %s", e.Status, e.Code, e.Message)
+}
+
+func (e *HTTPError) Unwrap() error {
+	return e.Err
+}
+
+// AsHTTPError maps err to the response it should produce. HTTPError is
+// returned as is; JSONError, BindError and validate.Errors get their
+// 4xx status and code; anything else is a 500 "internal_error" wrapping
+// err.
+func AsHTTPError(err error) *HTTPError {
+	var (
+		httpErr   *HTTPError
+		jsonErr   *JSONError
+		bindErr   *BindError
+		fieldErrs validate.Errors
+	)
+	switch {
+	case errors.As(err, &httpErr):
+		return httpErr
+	case errors.As(err, &fieldErrs):
+		return &HTTPError{Status: http.StatusUnprocessableEntity, Code: "validation_failed",
+			Message: "Request validation failed", Details: fieldErrs, Err: err}
+	case errors.As(err, &bindErr):
+		field := bindErr.Key
+		if field == "" {
+			field = bindErr.Field
+		}
+		return &HTTPError{Status: http.StatusUnprocessableEntity, Code: "validation_failed",
+			Message: "Request validation failed", Err: err,
+			Details: validate.Errors{{Field: field, Code: "invalid", Message: bindErr.Error()}}}
+	case errors.As(err, &jsonErr):
+		return &HTTPError{Status: jsonErr.Status, Code: string(jsonErr.Kind), Message: jsonErr.Error(), Err: err}
+	}
+	return &HTTPError{Status: http.StatusInternalServerError, Code: "internal_error",
+		Message: http.StatusText(http.StatusInternalServerError), Err: err}
+}
+
+// ErrorHandler writes the response for an error returned by a HandlerE or
+// passed to Context.Error.
+type ErrorHandler func(ctx *Context, err error)
+
+// ErrorHandlerConfig configures NewErrorHandler.
+type ErrorHandlerConfig struct {
+	// Logger receives one line per error. Defaults to log.Default().
+	Logger *log.Logger
+	// Development adds the underlying error text to responses. Leave it
+	// off in production: 5xx responses then only carry the status text.
+	Development bool
+}
+
+// NewErrorHandler returns the standard ErrorHandler. It maps errors with
+// AsHTTPError, logs them with the request ID set by the RequestID
+// middleware and answers in HTML when the client prefers text/html over
+// JSON:
+//
+//	{"error": {"code": "user_not_found", "message": "User not found", "request_id": "..."}}
+//
+// If the handler already started the response, the error is only logged.
+func NewErrorHandler(cfg ErrorHandlerConfig) ErrorHandler {
+	if cfg.Logger == nil {
+		cfg.Logger = log.Default()
+	}
+	return func(ctx *Context, err error) {
+		he := AsHTTPError(err)
+		requestID, _ := ctx.GetString("requestID")
+		req := ctx.Request()
+		cfg.Logger.Printf("[%s] %s %s %d %v", requestID, req.Method, req.URL.Path, he.Status, err)
+
+		if ctx.Response().Written() {
+			return
+		}
+
+		body := map[string]any{
+			"code":    he.Code,
+			"message": he.Message,
+		}
+		if he.Status >= http.StatusInternalServerError && !cfg.Development {
+			body["message"] = http.StatusText(he.Status)
+		} else if he.Details != nil {
+			body["details"] = he.Details
+		}
+		if cfg.Development && he.Err != nil {
+			body["debug"] = he.Err.Error()
+		}
+		if requestID != "" {
+			body["request_id"] = requestID
+		}
+
+		if prefersHTML(req) {
+			writeHTMLError(ctx, he.Status, body)
+			return
+		}
+		ctx.JSON(he.Status, map[string]any{"error": body})
+	}
+}
+
+// defaultErrorHandler is used by routers without SetErrorHandler.
+var defaultErrorHandler = NewErrorHandler(ErrorHandlerConfig{})
+
+// SetErrorHandler replaces the handler that renders errors returned by
+// HandlerE routes and passed to Context.Error.
+func (r *Router) SetErrorHandler(h ErrorHandler) {
+	root := r.root()
+	root.mu.Lock()
+	defer root.mu.Unlock()
+	root.errorHandler = h
+}
+
+// Error hands err to the router's ErrorHandler. It lets plain Handlers
+// and middleware share the central error rendering.
+func (c *Context) Error(err error) {
+	h := defaultErrorHandler
+	if c.router != nil {
+		c.router.mu.RLock()
+		if c.router.errorHandler != nil {
+			h = c.router.errorHandler
+		}
+		c.router.mu.RUnlock()
+	}
+	h(c, err)
+}
+
+// E adapts a HandlerE to a Handler that passes a non-nil error to the
+// router's ErrorHandler.
+func E(h HandlerE) Handler {
+	if h == nil {
+		return nil
+	}
+	return func(ctx *Context) {
+		if err := h(ctx); err != nil {
+			ctx.Error(err)
+		}
+	}
+}
+
+// prefersHTML reports whether the Accept header ranks text/html above
+// application/json.
+func prefersHTML(req *http.Request) bool {
+	accept := req.Header.Get("Accept")
+	if accept == "" {
+		return false
+	}
+	preferred := negotiate(accept, []Renderer{JSONRenderer{}, HTMLRenderer{}})
+	return len(preferred) > 0 && preferred[0].ContentType() == HTMLRenderer{}.ContentType()
+}
+
+func writeHTMLError(ctx *Context, status int, body map[string]any) {
+	ctx.ResponseWriter().Header().Set("Content-Type", "text/html; charset=utf-8")
+	ctx.Status(status)
+
+	title := fmt.Sprintf("%d %s", status, http.StatusText(status))
+	fmt.Fprintf(ctx.ResponseWriter(), "<!doctype html>\n<meta charset=\"utf-8\">\n<title>%s</title>\n<h1>%s</h1>\n<p>%s</p>\n",
+		html.EscapeString(title), html.EscapeString(title), html.EscapeString(fmt.Sprint(body["message"])))
+	for _, key := range []string{"debug", "request_id"} {
+		if v, ok := body[key]; ok {
+			fmt.Fprintf(ctx.ResponseWriter(), "<p><small>%s: %s</small></p>\n", key, html.EscapeString(fmt.Sprint(v)))
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package router
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+)
+
+// hostRoute is a Host sub-router together with its compiled pattern.
+type hostRoute struct {
+	pattern string
+	re      *regexp.Regexp
+	names   []string
+	router  *Router
+}
+
+// Host returns a sub-router whose routes only match requests for the given
+// host pattern. A "{name}" placeholder matches one DNS label and is exposed
+// through Context.Param:
+//
+//	tenant := r.Host("{tenant}.example.com")
+//	tenant.GET("/", func(ctx *router.Context) { ctx.Param("tenant") })
+//
+// Hosts are matched case-insensitively, ignoring the port unless the
+// pattern has one. Patterns are tried in registration order; requests that
+// match no host route fall back to the routes registered directly on the
+// router. Calling Host again with the same pattern returns the same
+// sub-router. Router-level middleware applies to host routes as well.
+func (r *Router) Host(pattern string) *Router {
+	root := r.root()
+	root.mu.Lock()
+	defer root.mu.Unlock()
+
+	for _, h := range root.hosts {
+		if h.pattern == pattern {
+			return h.router
+		}
+	}
+
+	re, names := compileHostPattern(pattern)
+	sub := &Router{
+		prefix:           r.prefix,
+		parent:           r,
+		host:             pattern,
+		tree:             &node{},
+		notFound:         r.notFound,
+		methodNotAllowed: r.methodNotAllowed,
+	}
+	root.hosts = append(root.hosts, &hostRoute{
+		pattern: pattern,
+		re:      re,
+		names:   names,
+		router:  sub,
+	})
+	return sub
+}
+
+// compileHostPattern turns "{tenant}.example.com" into an anchored,
+// case-insensitive regular expression and the list of placeholder names.
+func compileHostPattern(pattern string) (*regexp.Regexp, []string) {
+	var (
+		b     strings.Builder
+		names []string
+	)
+	b.WriteString("(?i)^")
+	rest := pattern
+	for {
+		open := strings.IndexByte(rest, '{')
+		if open < 0 {
+			b.WriteString(regexp.QuoteMeta(rest))
+			break
+		}
+		end := strings.IndexByte(rest[open:], '}')
+		if end < 0 {
+			panic(fmt.Sprintf("router: unterminated placeholder in host pattern %q", pattern))
+		}
+		name := rest[open+1 : open+end]
+		if name == "" {
+			panic(fmt.Sprintf("router: empty placeholder in host pattern %q", pattern))
+		}
+		b.WriteString(regexp.QuoteMeta(rest[:open]))
+		b.WriteString(`([^.:]+)`)
+		names = append(names, name)
+		rest = rest[open+end+1:]
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String()), names
+}
+
+// matchHost returns the host route matching host, appending its
+// placeholder values to params; the caller holds the router lock.
+func (r *Router) matchHost(host string, params *[]pathParam) *hostRoute {
+	bare := host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		bare = h
+	}
+	for _, h := range r.hosts {
+		candidate := bare
+		if strings.Contains(h.pattern, ":") {
+			candidate = host
+		}
+		m := h.re.FindStringSubmatch(candidate)
+		if m == nil {
+			continue
+		}
+		for i, name := range h.names {
+			*params = append(*params, pathParam{key: name, value: m[i+1]})
+		}
+		return h
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package router
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// JSONOptions controls how request bodies are decoded by BindJSON and Bind.
+// They are set for the whole router with Options.JSON and overridden per
+// route with Route.JSONOptions.
+type JSONOptions struct {
+	// MaxBytes limits the body size; larger bodies fail with
+	// JSONTooLarge. Zero or negative means no limit.
+	MaxBytes int64
+	// DisallowUnknownFields rejects object keys that don't match a field
+	// of the destination struct.
+	DisallowUnknownFields bool
+	// UseNumber decodes numbers into interface{} values as json.Number
+	// instead of float64.
+	UseNumber bool
+	// RequireContentType rejects requests whose Content-Type is not
+	// application/json (or a +json type) with JSONUnsupportedMediaType.
+	RequireContentType bool
+	// RejectTrailingData rejects bodies with anything but whitespace after
+	// the first JSON value.
+	RejectTrailingData bool
+}
+
+// DefaultJSONOptions returns the JSONOptions used by New. Every check is
+// off, so bodies decode as with a plain json.Decoder; set MaxBytes and
+// RejectTrailingData to bound and tighten decoding:
+//
+//	opts := router.DefaultOptions()
+//	opts.JSON.MaxBytes = 1 << 20
+//	opts.JSON.RejectTrailingData = true
+//	r.SetOptions(opts)
+func DefaultJSONOptions() JSONOptions {
+	return JSONOptions{}
+}
+
+// JSONErrorKind classifies a JSONError. Its value doubles as a
+// machine-readable error code.
+type JSONErrorKind string
+
+const (
+	// JSONSyntax is malformed JSON, including an empty body.
+	JSONSyntax JSONErrorKind = "invalid_json"
+	// JSONTypeMismatch is a value of the wrong type for its field.
+	JSONTypeMismatch JSONErrorKind = "type_mismatch"
+	// JSONUnknownField is a key with no matching field, reported when
+	// DisallowUnknownFields is set.
+	JSONUnknownField JSONErrorKind = "unknown_field"
+	// JSONTrailingData is data after the first JSON value.
+	JSONTrailingData JSONErrorKind = "trailing_data"
+	// JSONTooLarge is a body over MaxBytes.
+	JSONTooLarge JSONErrorKind = "body_too_large"
+	// JSONUnsupportedMediaType is a missing or non-JSON Content-Type,
+	// reported when RequireContentType is set.
+	JSONUnsupportedMediaType JSONErrorKind = "unsupported_media_type"
+)
+
+// JSONError reports why a JSON request body could not be decoded.
+type JSONError struct {
+	Kind JSONErrorKind
+	// Status is the HTTP status to answer with: 413 for JSONTooLarge, 415
+	// for JSONUnsupportedMediaType and 400 otherwise.
+	Status int
+	// Field is the dotted path of the offending field, for
+	// JSONTypeMismatch and JSONUnknownField.
+	Field string
+	// Offset is the byte offset in the body where the error was detected.
+	Offset int64
+	Err    error
+}
+
+func (e *JSONError) Error() string {
+	switch e.Kind {
+	case JSONTypeMismatch:
+		return fmt.Sprintf("json: field %q at offset %d: %v", e.Field, e.Offset, e.Err)
+	case JSONUnknownField:
+		return fmt.Sprintf("json: unknown field %q", e.Field)
+	case JSONSyntax, JSONTrailingData:
+		return fmt.Sprintf("json: %v at offset %d", e.Err, e.Offset)
+	}
+	return "json: " + e.Err.Error()
+}
+
+func (e *JSONError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	errEmptyBody    = fmt.Errorf("request body is empty: %w", io.EOF)
+	errTrailingData = errors.New("unexpected data after top-level value")
+)
+
+// jsonOptions returns the JSON options of the matched route, else those
+// of the router.
+func (c *Context) jsonOptions() JSONOptions {
+	if c.route == nil {
+		return DefaultJSONOptions()
+	}
+	root := c.route.group.root()
+	root.mu.RLock()
+	defer root.mu.RUnlock()
+	if c.route.json != nil {
+		return *c.route.json
+	}
+	return root.opts.JSON
+}
+
+// decodeJSON decodes the request body into dest according to the JSON
+// options in effect. allowEmpty treats an empty body as success.
+func (c *Context) decodeJSON(dest any, allowEmpty bool) error {
+	opts := c.jsonOptions()
+
+	if opts.RequireContentType && !isJSONRequest(c.req) {
+		return &JSONError{
+			Kind:   JSONUnsupportedMediaType,
+			Status: http.StatusUnsupportedMediaType,
+			Err:    fmt.Errorf("unsupported Content-Type %q, expected application/json", c.req.Header.Get("Content-Type")),
+		}
+	}
+
+	body := c.req.Body
+	if body == nil {
+		body = http.NoBody
+	}
+	if opts.MaxBytes > 0 {
+		body = http.MaxBytesReader(c.res, body, opts.MaxBytes)
+		c.req.Body = body
+	}
+
+	dec := json.NewDecoder(body)
+	if opts.DisallowUnknownFields {
+		dec.DisallowUnknownFields()
+	}
+	if opts.UseNumber {
+		dec.UseNumber()
+	}
+
+	if err := dec.Decode(dest); err != nil {
+		if errors.Is(err, io.EOF) {
+			if allowEmpty {
+				return nil
+			}
+			return &JSONError{Kind: JSONSyntax, Status: http.StatusBadRequest, Err: errEmptyBody}
+		}
+		return jsonError(err, dec.InputOffset())
+	}
+
+	if opts.RejectTrailingData {
+		if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				return jsonError(err, dec.InputOffset())
+			}
+			return &JSONError{Kind: JSONTrailingData, Status: http.StatusBadRequest, Offset: dec.InputOffset(), Err: errTrailingData}
+		}
+	}
+	return nil
+}
+
+// jsonError classifies an error returned by json.Decoder.Decode.
+func jsonError(err error, offset int64) *JSONError {
+	var (
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+		tooLarge  *http.MaxBytesError
+	)
+	switch {
+	case errors.As(err, &tooLarge):
+		return &JSONError{Kind: JSONTooLarge, Status: http.StatusRequestEntityTooLarge, Offset: offset,
+			Err: fmt.Errorf("request body exceeds %d bytes", tooLarge.Limit)}
+	case errors.As(err, &syntaxErr):
+		return &JSONError{Kind: JSONSyntax, Status: http.StatusBadRequest, Offset: syntaxErr.Offset, Err: err}
+	case errors.As(err, &typeErr):
+		return &JSONError{Kind: JSONTypeMismatch, Status: http.StatusBadRequest, Field: typeErr.Field, Offset: typeErr.Offset,
+			Err: fmt.Errorf("cannot use %s as %s", typeErr.Value, typeErr.Type)}
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return &JSONError{Kind: JSONSyntax, Status: http.StatusBadRequest, Offset: offset, Err: err}
+	}
+	// DisallowUnknownFields errors are untyped: `json: unknown field "x"`.
+	if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		return &JSONError{Kind: JSONUnknownField, Status: http.StatusBadRequest, Field: strings.Trim(field, `"`), Offset: offset, Err: err}
+	}
+	return &JSONError{Kind: JSONSyntax, Status: http.StatusBadRequest, Offset: offset, Err: err}
+}
+
+func isJSONRequest(req *http.Request) bool {
+	mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
@@ -0,0 +1,196 @@
+package router
+
+import (
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// TrailingSlash controls how a trailing slash affects route matching.
+type TrailingSlash int
+
+const (
+	// TrailingSlashIgnore serves "/users/" and "/users" with whichever of
+	// the two is registered. This is the default.
+	TrailingSlashIgnore TrailingSlash = iota
+	// TrailingSlashRedirect redirects to the registered form.
+	TrailingSlashRedirect
+	// TrailingSlashStrict treats "/users/" and "/users" as distinct paths.
+	TrailingSlashStrict
+)
+
+// Options holds path-matching and request-decoding behaviour for a Router.
+type Options struct {
+	// TrailingSlash selects how "/users/" relates to "/users".
+	TrailingSlash TrailingSlash
+	// RedirectCleanPath redirects paths containing "//", "." or ".."
+	// segments to their path.Clean form.
+	RedirectCleanPath bool
+	// CaseInsensitive redirects a request whose path only matches a route
+	// when compared case-insensitively to the registered spelling.
+	CaseInsensitive bool
+	// JSON configures body decoding for routes without their own
+	// Route.JSONOptions.
+	JSON JSONOptions
+}
+
+// DefaultOptions returns the Options used by New.
+func DefaultOptions() Options {
+	return Options{
+		TrailingSlash:     TrailingSlashIgnore,
+		RedirectCleanPath: true,
+		CaseInsensitive:   false,
+		JSON:              DefaultJSONOptions(),
+	}
+}
+
+// SetOptions replaces the options of the router. Start from
+// DefaultOptions to change a single setting.
+func (r *Router) SetOptions(opts Options) {
+	root := r.root()
+	root.mu.Lock()
+	defer root.mu.Unlock()
+	root.opts = opts
+}
+
+// requestPath returns the path to match. When the URL has a RawPath (i.e.
+// it contains escapes such as %2F that change its meaning), the escaped
+// form is returned and raw is true.
+func requestPath(u *url.URL) (p string, raw bool) {
+	if u.RawPath != "" {
+		return u.EscapedPath(), true
+	}
+	if u.Path == "" {
+		return "/", false
+	}
+	return u.Path, false
+}
+
+// cleanPath is path.Clean that keeps a trailing slash.
+func cleanPath(p string) string {
+	if p == "" {
+		return "/"
+	}
+	if p[0] != '/' {
+		p = "/" + p
+	}
+	c := path.Clean(p)
+	if strings.HasSuffix(p, "/") && c != "/" {
+		c += "/"
+	}
+	return c
+}
+
+// toggleSlash adds or removes the trailing slash of p.
+func toggleSlash(p string) string {
+	if strings.HasSuffix(p, "/") {
+		return strings.TrimSuffix(p, "/")
+	}
+	return p + "/"
+}
+
+// resolve matches req against the routes, applying the configured
+// path fix-ups. A non-empty redirect is the canonical path the client
+// should be sent to instead.
+func (r *Router) resolve(req *http.Request, params *[]pathParam) (match routeMatch, redirect string) {
+	r.mu.RLock()
+	opts := r.opts
+	r.mu.RUnlock()
+
+	p, raw := requestPath(req.URL)
+	if p == "*" {
+		// The asterisk form ("OPTIONS *") addresses the server, not a
+		// path: no route matches it and it must not be redirected.
+		return routeMatch{}, ""
+	}
+	if opts.RedirectCleanPath {
+		if c := cleanPath(p); c != p {
+			if target, ok := redirectTarget(c, raw); ok {
+				return routeMatch{}, target
+			}
+			return routeMatch{}, ""
+		}
+	}
+
+	match = r.findRoute(req.Method, req.Host, p, raw, params)
+	if match.route != nil || len(match.candidates) > 0 {
+		return match, ""
+	}
+
+	paths := []string{p}
+	if opts.TrailingSlash != TrailingSlashStrict && p != "/" {
+		alt := toggleSlash(p)
+		if m := r.findRoute(req.Method, req.Host, alt, raw, params); m.route != nil {
+			if opts.TrailingSlash != TrailingSlashRedirect {
+				return m, ""
+			}
+			*params = (*params)[:0]
+			if target, ok := redirectTarget(alt, raw); ok {
+				return routeMatch{}, target
+			}
+			return routeMatch{}, ""
+		}
+		paths = append(paths, alt)
+	}
+
+	if opts.CaseInsensitive {
+		for _, candidate := range paths {
+			if fixed, ok := r.findFold(req.Method, req.Host, candidate, raw); ok {
+				if target, ok := redirectTarget(fixed, raw); ok {
+					return routeMatch{}, target
+				}
+				break
+			}
+		}
+	}
+
+	return match, ""
+}
+
+// redirectTarget turns a fixed-up request path into a Location value. A
+// decoded path is escaped again, so characters such as '\' and '?' that
+// arrived as %5C and %3F stay part of the path. Targets that a browser
+// would read as another host ("//evil.com", "/\evil.com") are refused.
+func redirectTarget(p string, raw bool) (string, bool) {
+	if !raw {
+		p = (&url.URL{Path: p}).EscapedPath()
+	}
+	if strings.HasPrefix(p, "//") || strings.HasPrefix(p, "/\\") {
+		return "", false
+	}
+	return p, true
+}
+
+// findFold looks p up case-insensitively in the host tree, then the default
+// tree, and returns the canonical path.
+func (r *Router) findFold(method, host, p string, raw bool) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	trees := []*node{r.tree}
+	var hostParams []pathParam
+	if h := r.matchHost(host, &hostParams); h != nil {
+		trees = []*node{h.router.tree, r.tree}
+	}
+	for _, tree := range trees {
+		if canonical, ok := tree.findFold(method, p, raw); ok {
+			return canonical, true
+		}
+	}
+	return "", false
+}
+
+// redirectTo sends the client to target, keeping the query string. GET and
+// HEAD get a 301; other methods a 308 so the method and body are preserved.
+func redirectTo(ctx *Context, target string) {
+	req := ctx.Request()
+	if req.URL.RawQuery != "" {
+		target += "?" + req.URL.RawQuery
+	}
+	code := http.StatusPermanentRedirect
+	if req.Method == http.MethodGet || req.Method == http.MethodHead {
+		code = http.StatusMovedPermanently
+	}
+	http.Redirect(ctx.ResponseWriter(), req, target, code)
+}
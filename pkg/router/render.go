@@ -0,0 +1,296 @@
+package router
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"html/template"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Renderer writes response data in one media type. Context.Negotiate
+// picks among the router's renderers according to the Accept header.
+type Renderer interface {
+	// ContentType is the Content-Type header of rendered responses, e.g.
+	// "application/json" or "text/html; charset=utf-8".
+	ContentType() string
+	// Render writes data to w, or returns an error if data cannot be
+	// represented in this format.
+	Render(w io.Writer, data any) error
+}
+
+// defaultRenderers are the renderers of a new Router, in order of
+// preference when the client accepts several equally.
+func defaultRenderers() []Renderer {
+	return []Renderer{JSONRenderer{}, XMLRenderer{}, TextRenderer{}, CSVRenderer{}}
+}
+
+// JSONRenderer renders data with encoding/json.
+type JSONRenderer struct{}
+
+func (JSONRenderer) ContentType() string { return "application/json" }
+
+func (JSONRenderer) Render(w io.Writer, data any) error {
+	return json.NewEncoder(w).Encode(data)
+}
+
+// XMLRenderer renders data with encoding/xml. Maps are not supported.
+type XMLRenderer struct{}
+
+func (XMLRenderer) ContentType() string { return "application/xml" }
+
+func (XMLRenderer) Render(w io.Writer, data any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	return xml.NewEncoder(w).Encode(data)
+}
+
+// TextRenderer renders data as plain text using its default fmt format,
+// so types implementing fmt.Stringer control their own text.
+type TextRenderer struct{}
+
+func (TextRenderer) ContentType() string { return "text/plain; charset=utf-8" }
+
+func (TextRenderer) Render(w io.Writer, data any) error {
+	_, err := fmt.Fprintln(w, data)
+	return err
+}
+
+// HTMLRenderer renders data with an html/template. It is not registered
+// by default:
+//
+//	r.RegisterRenderer(router.HTMLRenderer{Template: tmpl, Name: "item.html"})
+type HTMLRenderer struct {
+	Template *template.Template
+	// Name selects the template to execute; empty executes Template.
+	Name string
+}
+
+func (HTMLRenderer) ContentType() string { return "text/html; charset=utf-8" }
+
+func (h HTMLRenderer) Render(w io.Writer, data any) error {
+	if h.Name == "" {
+		return h.Template.Execute(w, data)
+	}
+	return h.Template.ExecuteTemplate(w, h.Name, data)
+}
+
+// CSVRenderer renders [][]string, or a slice of structs as a header row
+// of field names (or `csv:"name"` tags) followed by one row per element.
+type CSVRenderer struct{}
+
+func (CSVRenderer) ContentType() string { return "text/csv; charset=utf-8" }
+
+func (CSVRenderer) Render(w io.Writer, data any) error {
+	cw := csv.NewWriter(w)
+	if rows, ok := data.([][]string); ok {
+		return cw.WriteAll(rows)
+	}
+
+	v := reflect.ValueOf(data)
+	for v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return fmt.Errorf("csv: cannot render %T", data)
+	}
+	elem := v.Type().Elem()
+	for elem.Kind() == reflect.Pointer {
+		elem = elem.Elem()
+	}
+	if elem.Kind() != reflect.Struct {
+		return fmt.Errorf("csv: cannot render %T", data)
+	}
+
+	var (
+		header []string
+		fields []int
+	)
+	for i := 0; i < elem.NumField(); i++ {
+		sf := elem.Field(i)
+		name := sf.Tag.Get("csv")
+		if !sf.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		header = append(header, name)
+		fields = append(fields, i)
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	row := make([]string, len(fields))
+	for i := 0; i < v.Len(); i++ {
+		item := v.Index(i)
+		for item.Kind() == reflect.Pointer {
+			item = item.Elem()
+		}
+		for j, f := range fields {
+			row[j] = fmt.Sprint(item.Field(f).Interface())
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// RegisterRenderer adds a renderer available to Context.Negotiate. A
+// renderer for a media type that is already registered replaces it.
+func (r *Router) RegisterRenderer(rd Renderer) {
+	root := r.root()
+	root.mu.Lock()
+	defer root.mu.Unlock()
+
+	// Copy on write: Negotiate reads the slice without holding the lock.
+	renderers := make([]Renderer, 0, len(root.renderers)+1)
+	mediaType := baseMediaType(rd.ContentType())
+	replaced := false
+	for _, existing := range root.renderers {
+		if baseMediaType(existing.ContentType()) == mediaType {
+			existing, replaced = rd, true
+		}
+		renderers = append(renderers, existing)
+	}
+	if !replaced {
+		renderers = append(renderers, rd)
+	}
+	root.renderers = renderers
+}
+
+// Negotiate renders data with the registered renderer that best matches
+// the request's Accept header, honouring q-values and preferring exact
+// media types over wildcards. Among equally acceptable renderers the
+// earliest registered wins; one that cannot render data (e.g. XML for a
+// map) is skipped in favour of the next. Without an Accept header the
+// first renderer is used. When nothing acceptable can render data the
+// response is 406 Not Acceptable.
+func (c *Context) Negotiate(status int, data any) {
+	c.res.Header().Add("Vary", "Accept")
+
+	var buf bytes.Buffer
+	for _, rd := range negotiate(c.req.Header.Get("Accept"), c.renderers()) {
+		buf.Reset()
+		if err := rd.Render(&buf, data); err != nil {
+			continue
+		}
+		c.res.Header().Set("Content-Type", rd.ContentType())
+		c.Status(status)
+		c.res.Write(buf.Bytes())
+		return
+	}
+
+	c.JSON(http.StatusNotAcceptable, map[string]string{
+		"error": "Not Acceptable",
+	})
+}
+
+func (c *Context) renderers() []Renderer {
+	if c.router == nil {
+		return defaultRenderers()
+	}
+	c.router.mu.RLock()
+	defer c.router.mu.RUnlock()
+	return c.router.renderers
+}
+
+// mediaRange is one entry of an Accept header.
+type mediaRange struct {
+	typ, subtype string
+	q            float64
+}
+
+// negotiate returns the acceptable renderers, most preferred first.
+func negotiate(accept string, renderers []Renderer) []Renderer {
+	if strings.TrimSpace(accept) == "" {
+		return renderers
+	}
+	ranges := parseAccept(accept)
+
+	type candidate struct {
+		rd Renderer
+		q  float64
+	}
+	var candidates []candidate
+	for _, rd := range renderers {
+		typ, subtype, _ := strings.Cut(baseMediaType(rd.ContentType()), "/")
+		// The most specific matching range decides the quality. A +json
+		// range, such as application/vnd.acme.v2+json or
+		// application/problem+json, is served by the JSON renderer. Other
+		// suffixes are left alone: browsers send application/xhtml+xml.
+		best, specificity := 0.0, -1
+		for _, mr := range ranges {
+			s := -1
+			switch {
+			case mr.typ == typ && mr.subtype == subtype:
+				s = 3
+			case mr.typ == typ && subtype == "json" && strings.HasSuffix(mr.subtype, "+json"):
+				s = 2
+			case mr.typ == typ && mr.subtype == "*":
+				s = 1
+			case mr.typ == "*" && mr.subtype == "*":
+				s = 0
+			}
+			if s > specificity {
+				best, specificity = mr.q, s
+			}
+		}
+		if specificity >= 0 && best > 0 {
+			candidates = append(candidates, candidate{rd: rd, q: best})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].q > candidates[j].q
+	})
+	result := make([]Renderer, len(candidates))
+	for i, c := range candidates {
+		result[i] = c.rd
+	}
+	return result
+}
+
+func parseAccept(accept string) []mediaRange {
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, _ := strings.Cut(part, ";")
+		typ, subtype, ok := strings.Cut(strings.ToLower(strings.TrimSpace(mediaType)), "/")
+		if !ok {
+			continue
+		}
+		mr := mediaRange{typ: typ, subtype: subtype, q: 1}
+		for _, param := range strings.Split(params, ";") {
+			key, value, _ := strings.Cut(param, "=")
+			if strings.TrimSpace(key) != "q" {
+				continue
+			}
+			if q, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+				mr.q = q
+			}
+		}
+		ranges = append(ranges, mr)
+	}
+	return ranges
+}
+
+// baseMediaType returns the lower-cased media type without parameters.
+func baseMediaType(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(contentType))
+	}
+	return mediaType
+}
@@ -0,0 +1,147 @@
+package router
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Response is the http.ResponseWriter handed to handlers. It records the
+// status code, the number of body bytes and when the first byte was
+// written, whichever way the response is produced: Context helpers,
+// http.ServeContent, templates writing to ctx.ResponseWriter(), or
+// wrapped net/http handlers.
+//
+// It implements http.Flusher, http.Hijacker, http.Pusher and io.ReaderFrom
+// by delegating to the underlying writer, and Unwrap for
+// http.ResponseController, so streaming, WebSocket upgrades and sendfile
+// keep working.
+type Response struct {
+	w         http.ResponseWriter
+	status    int
+	size      int64
+	written   bool
+	firstByte time.Time
+}
+
+// reset points the Response at a new underlying writer.
+func (rw *Response) reset(w http.ResponseWriter) {
+	*rw = Response{w: w, status: http.StatusOK}
+}
+
+// Status returns the status code sent, or 200 if none has been sent yet.
+// A hijacked connection reports 101 unless a status was sent before.
+func (rw *Response) Status() int {
+	return rw.status
+}
+
+// Size returns the number of body bytes written.
+func (rw *Response) Size() int64 {
+	return rw.size
+}
+
+// Written reports whether the status line has been sent.
+func (rw *Response) Written() bool {
+	return rw.written
+}
+
+// FirstByte returns when the first body byte was written, or the zero
+// time if the body is still empty.
+func (rw *Response) FirstByte() time.Time {
+	return rw.firstByte
+}
+
+func (rw *Response) Header() http.Header {
+	return rw.w.Header()
+}
+
+// WriteHeader sends the status line. Informational 1xx statuses other
+// than 101 (e.g. 103 Early Hints) pass through without being recorded;
+// anything after the final status is ignored, as net/http would.
+func (rw *Response) WriteHeader(code int) {
+	if code >= 100 && code < 200 && code != http.StatusSwitchingProtocols {
+		rw.w.WriteHeader(code)
+		return
+	}
+	if rw.written {
+		return
+	}
+	rw.status = code
+	rw.written = true
+	rw.w.WriteHeader(code)
+}
+
+func (rw *Response) Write(b []byte) (int, error) {
+	rw.beforeBody()
+	n, err := rw.w.Write(b)
+	rw.size += int64(n)
+	return n, err
+}
+
+// ReadFrom lets io.Copy reach the underlying writer's ReadFrom, which
+// net/http implements with sendfile for files.
+func (rw *Response) ReadFrom(src io.Reader) (int64, error) {
+	rw.beforeBody()
+	var (
+		n   int64
+		err error
+	)
+	if rf, ok := rw.w.(io.ReaderFrom); ok {
+		n, err = rf.ReadFrom(src)
+	} else {
+		// Hide our own ReadFrom from io.Copy to avoid recursing.
+		n, err = io.Copy(struct{ io.Writer }{rw.w}, src)
+	}
+	rw.size += n
+	return n, err
+}
+
+// beforeBody sends an implicit 200 and records the first-byte time.
+func (rw *Response) beforeBody() {
+	if !rw.written {
+		rw.WriteHeader(http.StatusOK)
+	}
+	if rw.firstByte.IsZero() {
+		rw.firstByte = time.Now()
+	}
+}
+
+// Flush implements http.Flusher. It sends the status line if needed.
+func (rw *Response) Flush() {
+	rw.FlushError()
+}
+
+// FlushError is Flush reporting whether the underlying writer supports it;
+// http.ResponseController prefers it over Flush.
+func (rw *Response) FlushError() error {
+	if !rw.written {
+		rw.WriteHeader(http.StatusOK)
+	}
+	return http.NewResponseController(rw.w).Flush()
+}
+
+// Hijack implements http.Hijacker for protocols such as WebSocket that
+// take over the connection.
+func (rw *Response) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, buf, err := http.NewResponseController(rw.w).Hijack()
+	if err == nil && !rw.written {
+		rw.status = http.StatusSwitchingProtocols
+		rw.written = true
+	}
+	return conn, buf, err
+}
+
+// Push implements http.Pusher for HTTP/2 server push.
+func (rw *Response) Push(target string, opts *http.PushOptions) error {
+	if p, ok := rw.w.(http.Pusher); ok {
+		return p.Push(target, opts)
+	}
+	return http.ErrNotSupported
+}
+
+// Unwrap returns the underlying writer, for http.ResponseController.
+func (rw *Response) Unwrap() http.ResponseWriter {
+	return rw.w
+}
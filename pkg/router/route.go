@@ -0,0 +1,208 @@
+package router
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// packagePath is the import path of this package, used to skip its own
+// frames when recording where a route was registered.
+var packagePath = reflect.TypeOf(Route{}).PkgPath()
+
+// Route is a single method+path registration. The route methods on Router
+// return it so a registration can be refined in place:
+//
+//	r.GET("/admin/stats", stats, middleware.JWTAuth(secret)).
+//		Name("admin.stats").
+//		Meta("summary", "Dashboard statistics")
+type Route struct {
+	method string
+	host   string
+	path   string
+	// source is the file:line that registered the route.
+	source      string
+	group       *Router
+	handler     Handler
+	middlewares []Middleware
+	name        string
+	meta        map[string]any
+	// json overrides the router's Options.JSON when set.
+	json *JSONOptions
+	// in and out are the request and response types of a TypedHandler.
+	in, out reflect.Type
+	// chain is handler wrapped in every middleware that applies to it;
+	// rebuilt by Router.compose whenever middleware or routes change.
+	chain Handler
+}
+
+// Use adds middleware that applies to this route only. It runs inside the
+// router and group middleware, in the order given.
+func (rt *Route) Use(mw ...Middleware) *Route {
+	root := rt.group.root()
+	root.mu.Lock()
+	defer root.mu.Unlock()
+	rt.middlewares = append(rt.middlewares, mw...)
+	root.composed = false
+	return rt
+}
+
+// Name sets the route name used by Router.URL. Names are unique per
+// router, except that routes sharing a path (e.g. GET and POST /users)
+// may share a name. Reusing a name for a different path panics.
+func (rt *Route) Name(name string) *Route {
+	root := rt.group.root()
+	root.mu.Lock()
+	defer root.mu.Unlock()
+	if other := root.names[name]; other != nil && other.path != rt.path {
+		panic(fmt.Sprintf("router: route name %q for %s %s is already used by %s %s",
+			name, rt.method, rt.path, other.method, other.path))
+	}
+	if root.names == nil {
+		root.names = make(map[string]*Route)
+	}
+	rt.name = name
+	root.names[name] = rt
+	return rt
+}
+
+// Meta attaches a metadata value to the route. Handlers and middleware read
+// it with Context.RouteMeta; tooling reads it from the route listing.
+// Setting "hidden" to true leaves the route out of that listing.
+func (rt *Route) Meta(key string, value any) *Route {
+	root := rt.group.root()
+	root.mu.Lock()
+	defer root.mu.Unlock()
+	if rt.meta == nil {
+		rt.meta = make(map[string]any)
+	}
+	rt.meta[key] = value
+	return rt
+}
+
+// JSONOptions overrides the router's JSON decoding options for this
+// route, e.g. to accept larger uploads on a single endpoint.
+func (rt *Route) JSONOptions(opts JSONOptions) *Route {
+	root := rt.group.root()
+	root.mu.Lock()
+	defer root.mu.Unlock()
+	rt.json = &opts
+	return rt
+}
+
+// compose wraps the route handler in its own middleware, then in the
+// middleware of its group and of every ancestor, outermost (root) first.
+// Routes of a Versions sub-router stop at the version router: they run
+// inside the dispatcher route, which already applied the ancestors'.
+func (rt *Route) compose() Handler {
+	h := wrap(rt.handler, rt.middlewares)
+	for g := rt.group; g != nil; g = g.parent {
+		h = wrap(h, g.middlewares)
+		if g.version != "" && g.parent != nil && g.parent.version == "" {
+			break
+		}
+	}
+	return h
+}
+
+// callerSource returns the file:line of the first caller outside this
+// package, i.e. the application code that registered a route.
+func callerSource() string {
+	pcs := make([]uintptr, 16)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	for {
+		f, more := frames.Next()
+		if !strings.HasPrefix(f.Function, packagePath+".") {
+			return fmt.Sprintf("%s:%d", f.File, f.Line)
+		}
+		if !more {
+			return "unknown"
+		}
+	}
+}
+
+// value reads a metadata entry under the router lock.
+func (rt *Route) value(key string) (any, bool) {
+	root := rt.group.root()
+	root.mu.RLock()
+	defer root.mu.RUnlock()
+	v, ok := rt.meta[key]
+	return v, ok
+}
+
+// RouteInfo describes a registered route.
+type RouteInfo struct {
+	Method string
+	// Host is the Router.Host pattern the route is bound to, or "".
+	Host string
+	// Version is the Versions version the route belongs to, or "".
+	Version string
+	// Path is the full pattern including group prefixes, e.g. "/api/users/:id".
+	Path string
+	Name string
+	// Source is the file:line of the registration.
+	Source string
+	// Middleware counts every middleware that wraps the handler: router,
+	// group and per-route.
+	Middleware int
+	Meta       map[string]any
+	// Request and Response are the input and result types of a route
+	// registered with Typed, or nil.
+	Request  reflect.Type
+	Response reflect.Type
+}
+
+// Routes lists every registered route in tree order, with the methods of
+// each path sorted alphabetically. Routes marked Meta("hidden", true),
+// such as the dispatcher behind Versions, are left out.
+func (r *Router) Routes() []RouteInfo {
+	root := r.root()
+	root.mu.RLock()
+	defer root.mu.RUnlock()
+
+	var infos []RouteInfo
+	collect := func(rt *Route) {
+		if hidden, _ := rt.meta["hidden"].(bool); hidden {
+			return
+		}
+		infos = append(infos, rt.info())
+	}
+	root.tree.walk(collect)
+	for _, h := range root.hosts {
+		h.router.tree.walk(collect)
+	}
+	for _, v := range root.versioned {
+		v.tree.walk(collect)
+	}
+	return infos
+}
+
+// info snapshots the route; the caller holds the router lock.
+func (rt *Route) info() RouteInfo {
+	count := len(rt.middlewares)
+	for g := rt.group; g != nil; g = g.parent {
+		count += len(g.middlewares)
+	}
+
+	var meta map[string]any
+	if len(rt.meta) > 0 {
+		meta = make(map[string]any, len(rt.meta))
+		for k, v := range rt.meta {
+			meta[k] = v
+		}
+	}
+
+	return RouteInfo{
+		Method:     rt.method,
+		Host:       rt.host,
+		Version:    rt.group.version,
+		Path:       rt.path,
+		Name:       rt.name,
+		Source:     rt.source,
+		Middleware: count,
+		Meta:       meta,
+		Request:    rt.in,
+		Response:   rt.out,
+	}
+}
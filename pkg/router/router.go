@@ -2,46 +2,75 @@
 package router
 
 import (
+	"fmt"
 	"net/http"
+	"sort"
 	"strings"
 	"sync"
 )
 
-// Handler is the function signature for HTTP handlers.
+// Handler is the function signature for HTTP handlers. Use E for a
+// HandlerE and Router.HandleTyped for a TypedHandler.
 type Handler func(ctx *Context)
 
 type routeMatch struct {
-    handler Handler
-    params  map[string]string
+	route *Route
+	// handler is route.chain, read while the router lock is held.
+	handler Handler
+	// candidates are nodes whose path matched but which have no handler for
+	// the requested method; they drive the Allow header on 405 and OPTIONS.
+	candidates []*node
 }
 
+// anyMethods are the methods registered by Router.Any.
+var anyMethods = []string{
+	http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch,
+	http.MethodDelete, http.MethodHead, http.MethodOptions,
+}
 
 // Middleware is a function that wraps a Handler.
 type Middleware func(Handler) Handler
 
 // Router manages routes and middleware.
+//
+// A Router returned by Group shares the tree, routes and lock of its root.
+// Middleware is not baked into handlers at registration time: the chains
+// are composed lazily before the next request is served, so Use may be
+// called before or after the routes it should apply to.
 type Router struct {
 	prefix           string
+	host             string
+	version          string
 	parent           *Router
 	tree             *node
 	middlewares      []Middleware
 	mu               sync.RWMutex
 	notFound         Handler
 	methodNotAllowed Handler
-}
 
-// node represents a node in the radix tree.
-type node struct {
-	path      string
-	isParam   bool
-	paramName string
-	children  []*node
-	handlers  map[string]Handler
+	// The fields below are only used on the root router.
+	opts   Options
+	routes []*Route
+	names  map[string]*Route
+	hosts  []*hostRoute
+	// versioned are the Versions sub-routers; each owns a separate tree.
+	versioned    []*Router
+	renderers    []Renderer
+	errorHandler ErrorHandler
+	pool         sync.Pool
+	composed     bool
+	// notFoundChain, methodNotAllowedChain and optionsChain are the fallback
+	// handlers wrapped in the root middleware.
+	notFoundChain         Handler
+	methodNotAllowedChain Handler
+	optionsChain          Handler
 }
 
 // New creates a new Router instance.
 func New() *Router {
 	r := &Router{
+		opts:        DefaultOptions(),
+		renderers:   defaultRenderers(),
 		tree:        &node{},
 		middlewares: []Middleware{},
 		notFound: func(ctx *Context) {
@@ -55,233 +84,324 @@ func New() *Router {
 			})
 		},
 	}
+	r.pool.New = func() any {
+		return &Context{router: r, params: make([]pathParam, 0, 8)}
+	}
 	return r
 }
 
 // Group creates a new router group with the given prefix.
+// Middleware added to r later, even after the group's routes are
+// registered, still applies to the group.
 func (r *Router) Group(prefix string) *Router {
 	return &Router{
 		prefix:           r.prefix + prefix,
+		host:             r.host,
+		version:          r.version,
 		parent:           r,
 		tree:             r.tree,
-		middlewares:      r.middlewares,
 		notFound:         r.notFound,
 		methodNotAllowed: r.methodNotAllowed,
 	}
 }
+
+// root returns the router at the top of the group hierarchy, which owns
+// the shared state.
+func (r *Router) root() *Router {
+	for r.parent != nil {
+		r = r.parent
+	}
+	return r
+}
+
 // SetNotFound allows applications to override the default 404 handler.
 func (r *Router) SetNotFound(h Handler) {
-    r.mu.Lock()
-    defer r.mu.Unlock()
-    r.notFound = h
+	root := r.root()
+	root.mu.Lock()
+	defer root.mu.Unlock()
+	root.notFound = h
+	root.composed = false
 }
 
 // SetMethodNotAllowed allows applications to override the default 405 handler.
 func (r *Router) SetMethodNotAllowed(h Handler) {
-    r.mu.Lock()
-    defer r.mu.Unlock()
-    r.methodNotAllowed = h
+	root := r.root()
+	root.mu.Lock()
+	defer root.mu.Unlock()
+	root.methodNotAllowed = h
+	root.composed = false
 }
 
-// Use registers middleware that will be applied to all routes in this group.
+// Use registers middleware that will be applied to all routes in this group
+// and its sub-groups, regardless of whether they were registered before or
+// after this call. Middleware runs outer to inner: the root router's
+// middleware first, then each nested group's, each in the order given to Use.
+// Middleware on the root router also wraps the 404, 405 and automatic
+// OPTIONS responses.
 func (r *Router) Use(mw ...Middleware) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
+	root := r.root()
+	root.mu.Lock()
+	defer root.mu.Unlock()
 	r.middlewares = append(r.middlewares, mw...)
+	root.composed = false
 }
 
 // GET registers a GET route.
-func (r *Router) GET(path string, h Handler) {
-	r.addRoute(http.MethodGet, path, h)
+func (r *Router) GET(path string, h Handler, mw ...Middleware) *Route {
+	return r.addRoute(http.MethodGet, path, h, mw)
 }
 
 // POST registers a POST route.
-func (r *Router) POST(path string, h Handler) {
-	r.addRoute(http.MethodPost, path, h)
+func (r *Router) POST(path string, h Handler, mw ...Middleware) *Route {
+	return r.addRoute(http.MethodPost, path, h, mw)
 }
 
 // PUT registers a PUT route.
-func (r *Router) PUT(path string, h Handler) {
-	r.addRoute(http.MethodPut, path, h)
+func (r *Router) PUT(path string, h Handler, mw ...Middleware) *Route {
+	return r.addRoute(http.MethodPut, path, h, mw)
 }
 
 // DELETE registers a DELETE route.
-func (r *Router) DELETE(path string, h Handler) {
-	r.addRoute(http.MethodDelete, path, h)
+func (r *Router) DELETE(path string, h Handler, mw ...Middleware) *Route {
+	return r.addRoute(http.MethodDelete, path, h, mw)
 }
 
 // PATCH registers a PATCH route.
-func (r *Router) PATCH(path string, h Handler) {
-	r.addRoute(http.MethodPatch, path, h)
+func (r *Router) PATCH(path string, h Handler, mw ...Middleware) *Route {
+	return r.addRoute(http.MethodPatch, path, h, mw)
 }
 
-// Handler returns the HTTP handler for the router.
-func (r *Router) Handler() http.Handler {
-    return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
-        ctx := NewContext(w, req)
-
-        match := r.findRoute(req.Method, req.URL.Path)
-        if match.handler == nil {
-            // Check if it's a method-not-allowed or a true 404
-            if r.isPathRegistered(req.URL.Path) {
-                r.methodNotAllowed(ctx)
-            } else {
-                r.notFound(ctx)
-            }
-            return
-        }
-
-        // inject path params into context
-        for k, v := range match.params {
-            ctx.params[k] = v
-        }
-
-        match.handler(ctx)
-    })
+// HEAD registers a HEAD route. GET routes already answer HEAD requests, so
+// this is only needed to serve HEAD differently from GET.
+func (r *Router) HEAD(path string, h Handler, mw ...Middleware) *Route {
+	return r.addRoute(http.MethodHead, path, h, mw)
 }
 
+// OPTIONS registers an OPTIONS route, replacing the automatic response
+// that lists the allowed methods.
+func (r *Router) OPTIONS(path string, h Handler, mw ...Middleware) *Route {
+	return r.addRoute(http.MethodOptions, path, h, mw)
+}
 
-// addRoute adds a route with the given method and path.
-func (r *Router) addRoute(method, path string, h Handler) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
+// Handle registers a route for an arbitrary HTTP method.
+func (r *Router) Handle(method, path string, h Handler, mw ...Middleware) *Route {
+	return r.addRoute(strings.ToUpper(method), path, h, mw)
+}
 
-	fullPath := r.prefix + path
-	if !strings.HasPrefix(fullPath, "/") {
-		fullPath = "/" + fullPath
+// HandleTyped registers a TypedHandler for an arbitrary HTTP method. Its
+// request and response types are recorded in RouteInfo.
+func (r *Router) HandleTyped(method, path string, h *TypedHandler, mw ...Middleware) *Route {
+	var handler Handler
+	if h != nil {
+		handler = E(h.handle)
 	}
+	rt := r.addRoute(strings.ToUpper(method), path, handler, mw)
+	root := r.root()
+	root.mu.Lock()
+	rt.in, rt.out = h.in, h.out
+	root.mu.Unlock()
+	return rt
+}
 
-	// Apply middlewares to the handler
-	wrappedHandler := h
-	for i := len(r.middlewares) - 1; i >= 0; i-- {
-		wrappedHandler = r.middlewares[i](wrappedHandler)
+// Any registers the handler for GET, POST, PUT, PATCH, DELETE, HEAD and OPTIONS.
+// It returns one Route per method.
+func (r *Router) Any(path string, h Handler, mw ...Middleware) []*Route {
+	routes := make([]*Route, 0, len(anyMethods))
+	for _, method := range anyMethods {
+		routes = append(routes, r.addRoute(method, path, h, mw))
 	}
-
-	r.tree.insert(method, fullPath, wrappedHandler)
+	return routes
 }
 
-// findRoute finds a handler for the given method and path.
-func (r *Router) findRoute(method, path string) routeMatch {
-    r.mu.RLock()
-    defer r.mu.RUnlock()
+// Handler returns the HTTP handler for the router.
+// It may be called before routes and middleware are registered.
+//
+// Contexts are recycled between requests, so a handler must not keep its
+// *Context (or hand it to a goroutine) after it returns. Contexts passed on
+// as a context.Context are the exception: they are never recycled.
+func (r *Router) Handler() http.Handler {
+	root := r.root()
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodHead {
+			w = headResponseWriter{w}
+		}
+		ctx := root.pool.Get().(*Context)
+		ctx.reset(w, req)
+		defer root.release(ctx)
+
+		root.compose()
+		match, redirect := root.resolve(req, &ctx.params)
+		if redirect != "" {
+			root.mu.RLock()
+			h := wrap(func(ctx *Context) { redirectTo(ctx, redirect) }, root.middlewares)
+			root.mu.RUnlock()
+			h(ctx)
+			return
+		}
+		if match.route == nil {
+			// The path exists for other methods: answer OPTIONS ourselves,
+			// otherwise it's a 405. Without candidates it's a true 404.
+			root.mu.RLock()
+			notFound, methodNotAllowed, options := root.notFoundChain, root.methodNotAllowedChain, root.optionsChain
+			root.mu.RUnlock()
+
+			if len(match.candidates) == 0 {
+				notFound(ctx)
+				return
+			}
+			w.Header().Set("Allow", strings.Join(match.allowedMethods(), ", "))
+			if req.Method == http.MethodOptions {
+				options(ctx)
+				return
+			}
+			methodNotAllowed(ctx)
+			return
+		}
 
-    if r.tree == nil {
-        return routeMatch{}
-    }
+		ctx.route = match.route
+		match.handler(ctx)
+	})
+}
 
-    return r.tree.find(method, path)
+// release returns ctx to the pool unless it was used as a
+// context.Context, which may outlive the request.
+func (r *Router) release(ctx *Context) {
+	if !ctx.escaped.Load() {
+		r.pool.Put(ctx)
+	}
 }
 
+// addRoute adds a route with the given method and path.
+func (r *Router) addRoute(method, path string, h Handler, mw []Middleware) *Route {
+	root := r.root()
+	root.mu.Lock()
+	defer root.mu.Unlock()
 
-// isPathRegistered checks if a path is registered (for any method).
-func (r *Router) isPathRegistered(path string) bool {
-    r.mu.RLock()
-    defer r.mu.RUnlock()
+	fullPath := r.prefix + path
+	if !strings.HasPrefix(fullPath, "/") {
+		fullPath = "/" + fullPath
+	}
+	if h == nil {
+		panic(fmt.Sprintf("router: nil handler for %s %s", method, fullPath))
+	}
 
-    if r.tree == nil {
-        return false
-    }
+	rt := &Route{
+		method:      method,
+		host:        r.host,
+		path:        fullPath,
+		source:      callerSource(),
+		group:       r,
+		handler:     h,
+		middlewares: append([]Middleware(nil), mw...),
+	}
+	rt.chain = rt.compose()
 
-    methods := []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete, http.MethodPatch}
-    for _, method := range methods {
-        if match := r.tree.find(method, path); match.handler != nil {
-            return true
-        }
-    }
-    return false
+	r.tree.insert(method, fullPath, rt)
+	root.routes = append(root.routes, rt)
+	return rt
 }
 
+// compose rebuilds every middleware chain if routes or middleware changed
+// since the last request.
+func (r *Router) compose() {
+	r.mu.RLock()
+	composed := r.composed
+	r.mu.RUnlock()
+	if composed {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.composed {
+		return
+	}
 
-// node methods
-func (n *node) insert(method, path string, handler Handler) {
-	if n.children == nil {
-		n.children = []*node{}
+	for _, rt := range r.routes {
+		rt.chain = rt.compose()
 	}
+	r.notFoundChain = wrap(r.notFound, r.middlewares)
+	r.methodNotAllowedChain = wrap(r.methodNotAllowed, r.middlewares)
+	r.optionsChain = wrap(func(ctx *Context) {
+		ctx.Status(http.StatusNoContent)
+	}, r.middlewares)
+	r.composed = true
+}
 
-	// Split path into segments
-	segments := strings.Split(strings.Trim(path, "/"), "/")
-	n.insertRecursive(method, segments, handler)
+// wrap applies middlewares to h so that middlewares[0] runs first.
+func wrap(h Handler, middlewares []Middleware) Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		h = middlewares[i](h)
+	}
+	return h
 }
 
-func (n *node) insertRecursive(method string, segments []string, handler Handler) {
-	if len(segments) == 0 {
-		if n.handlers == nil {
-			n.handlers = make(map[string]Handler)
+// findRoute finds a handler for the given method, host and path,
+// appending path parameters to params. Routes of a matching Host
+// sub-router win; otherwise the default tree is used. A 405 from the host
+// tree is kept only if the default tree has no route either.
+func (r *Router) findRoute(method, host, path string, raw bool, params *[]pathParam) routeMatch {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	mark := len(*params)
+	var hostMatch routeMatch
+	if len(r.hosts) > 0 {
+		if h := r.matchHost(host, params); h != nil {
+			hostMatch = h.router.tree.find(method, path, raw, params)
+			if hostMatch.route == nil {
+				*params = (*params)[:mark]
+			}
 		}
-		n.handlers[method] = handler
-		return
 	}
 
-	segment := segments[0]
-	var child *node
-
-	// Check for existing child
-	for _, c := range n.children {
-		if c.path == segment {
-			child = c
-			break
+	match := hostMatch
+	if match.route == nil {
+		match = r.tree.find(method, path, raw, params)
+		if match.route == nil {
+			*params = (*params)[:mark]
+			if len(hostMatch.candidates) > 0 {
+				match = hostMatch
+			}
 		}
 	}
+	if match.route != nil {
+		match.handler = match.route.chain
+	}
+	return match
+}
 
-	// Create new child if not found
-	if child == nil {
-		child = &node{
-			path:    segment,
-			isParam: strings.HasPrefix(segment, ":"),
+// allowedMethods returns the sorted methods served by the matched
+// candidates, including the implicit HEAD and OPTIONS.
+func (m routeMatch) allowedMethods() []string {
+	set := map[string]bool{http.MethodOptions: true}
+	for _, n := range m.candidates {
+		for method := range n.handlers {
+			set[method] = true
 		}
-		if child.isParam {
-			child.paramName = segment[1:]
+		if n.handlers[http.MethodGet] != nil {
+			set[http.MethodHead] = true
 		}
-		n.children = append(n.children, child)
 	}
 
-	child.insertRecursive(method, segments[1:], handler)
+	methods := make([]string, 0, len(set))
+	for method := range set {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	return methods
 }
 
-// find returns the handler for a method+path and any path parameters.
-func (n *node) find(method, path string) routeMatch {
-    segments := strings.Split(strings.Trim(path, "/"), "/")
-    params := make(map[string]string)
-
-    h := n.findRecursive(method, segments, params)
-    if h == nil {
-        return routeMatch{}
-    }
+// headResponseWriter discards the body so GET handlers can answer HEAD.
+type headResponseWriter struct {
+	http.ResponseWriter
+}
 
-    return routeMatch{
-        handler: h,
-        params:  params,
-    }
+func (w headResponseWriter) Write(b []byte) (int, error) {
+	return len(b), nil
 }
 
-func (n *node) findRecursive(method string, segments []string, params map[string]string) Handler {
-    if len(segments) == 0 {
-        if n.handlers == nil {
-            return nil
-        }
-        return n.handlers[method]
-    }
-
-    segment := segments[0]
-
-    // 1) exact match first
-    for _, child := range n.children {
-        if !child.isParam && child.path == segment {
-            if h := child.findRecursive(method, segments[1:], params); h != nil {
-                return h
-            }
-        }
-    }
-
-    // 2) then param match
-    for _, child := range n.children {
-        if child.isParam {
-            params[child.paramName] = segment
-            if h := child.findRecursive(method, segments[1:], params); h != nil {
-                return h
-            }
-        }
-    }
-
-    return nil
+// Unwrap returns the underlying writer, for http.ResponseController.
+func (w headResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
 }
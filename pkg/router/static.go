@@ -0,0 +1,247 @@
+package router
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// staticParam is the catch-all that receives the file path below a Static prefix.
+const staticParam = "filepath"
+
+// fingerprintPattern matches names like app.3f9a2c1b.js or logo-3f9a2c1b.png.
+var fingerprintPattern = regexp.MustCompile(`[.-][0-9a-fA-F]{8,}\.[^/]+$`)
+
+// StaticOptions configures Router.Static. The zero value serves files with
+// index.html as the directory index and no directory listing.
+type StaticOptions struct {
+	// Index is the file served for directory requests. Defaults to "index.html".
+	Index string
+	// Browse enables an HTML listing for directories without an index file.
+	Browse bool
+	// SPA serves the root index file for paths that don't exist, so
+	// client-side routers can handle them.
+	SPA bool
+	// MaxAge sets Cache-Control max-age for regular files. Zero sends
+	// "no-cache", which still allows revalidation via ETag/Last-Modified.
+	MaxAge time.Duration
+	// Fingerprinted reports whether a file name carries a content hash and
+	// can be cached for a year as immutable. Defaults to matching names
+	// like app.3f9a2c1b.js.
+	Fingerprinted func(name string) bool
+}
+
+// Static serves files from fsys (an embed.FS, os.DirFS, ...) below prefix.
+// Paths are cleaned and validated centrally, so ".." can never escape fsys.
+// When the client accepts gzip, a precompressed "<name>.gz" sibling is
+// served in place of the file. Responses carry a content-hash ETag and,
+// when fsys provides one, Last-Modified; conditional and range requests
+// are handled by http.ServeContent.
+func (r *Router) Static(prefix string, fsys fs.FS, opts StaticOptions, mw ...Middleware) *Route {
+	if opts.Index == "" {
+		opts.Index = "index.html"
+	}
+	if opts.Fingerprinted == nil {
+		opts.Fingerprinted = fingerprintPattern.MatchString
+	}
+
+	s := &staticServer{fsys: fsys, opts: opts, router: r.root()}
+	prefix = strings.TrimSuffix(prefix, "/")
+	return r.GET(prefix+"/*"+staticParam, s.serve, mw...)
+}
+
+// staticServer serves one Static mount.
+type staticServer struct {
+	fsys   fs.FS
+	opts   StaticOptions
+	router *Router
+	etags  sync.Map // name -> etagEntry
+}
+
+// etagEntry is a cached ETag and the file version it was computed for.
+type etagEntry struct {
+	size    int64
+	modTime time.Time
+	etag    string
+}
+
+func (s *staticServer) serve(ctx *Context) {
+	name, ok := cleanStaticPath(ctx.Param(staticParam))
+	if !ok {
+		s.notFound(ctx)
+		return
+	}
+
+	info, err := fs.Stat(s.fsys, name)
+	if err == nil && info.IsDir() {
+		index := path.Join(name, s.opts.Index)
+		if indexInfo, err := fs.Stat(s.fsys, index); err == nil && !indexInfo.IsDir() {
+			s.serveFile(ctx, index, indexInfo)
+			return
+		}
+		if s.opts.Browse {
+			s.serveListing(ctx, name)
+			return
+		}
+		err = fs.ErrNotExist
+	}
+	if err != nil {
+		if s.opts.SPA {
+			if indexInfo, err := fs.Stat(s.fsys, s.opts.Index); err == nil && !indexInfo.IsDir() {
+				s.serveFile(ctx, s.opts.Index, indexInfo)
+				return
+			}
+		}
+		s.notFound(ctx)
+		return
+	}
+
+	s.serveFile(ctx, name, info)
+}
+
+// cleanStaticPath turns the captured request path into a valid fs.FS name.
+func cleanStaticPath(p string) (string, bool) {
+	name := strings.TrimPrefix(path.Clean("/"+p), "/")
+	if name == "" {
+		name = "."
+	}
+	return name, fs.ValidPath(name)
+}
+
+func (s *staticServer) serveFile(ctx *Context, name string, info fs.FileInfo) {
+	w := ctx.ResponseWriter()
+	h := w.Header()
+
+	if s.opts.Fingerprinted(path.Base(name)) {
+		h.Set("Cache-Control", "public, max-age=31536000, immutable")
+	} else if s.opts.MaxAge > 0 {
+		h.Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(s.opts.MaxAge.Seconds())))
+	} else {
+		h.Set("Cache-Control", "no-cache")
+	}
+
+	served := name
+	if gzInfo, err := fs.Stat(s.fsys, name+".gz"); err == nil && !gzInfo.IsDir() {
+		h.Add("Vary", "Accept-Encoding")
+		if acceptsGzip(ctx.Request()) {
+			served, info = name+".gz", gzInfo
+			h.Set("Content-Encoding", "gzip")
+			if ct := mime.TypeByExtension(path.Ext(name)); ct != "" {
+				h.Set("Content-Type", ct)
+			}
+		}
+	}
+
+	f, err := s.fsys.Open(served)
+	if err != nil {
+		s.notFound(ctx)
+		return
+	}
+	defer f.Close()
+
+	content, ok := f.(io.ReadSeeker)
+	if !ok {
+		b, err := io.ReadAll(f)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		content = bytes.NewReader(b)
+	}
+
+	if etag, err := s.etag(served, info, content); err == nil {
+		h.Set("ETag", etag)
+	}
+
+	http.ServeContent(w, ctx.Request(), name, info.ModTime(), content)
+}
+
+// etag returns a strong ETag derived from the file contents. It is
+// computed again when the file's size or modification time changes, so
+// edits in an os.DirFS are picked up. content is rewound afterwards.
+func (s *staticServer) etag(name string, info fs.FileInfo, content io.ReadSeeker) (string, error) {
+	if v, ok := s.etags.Load(name); ok {
+		e := v.(etagEntry)
+		if e.size == info.Size() && e.modTime.Equal(info.ModTime()) {
+			return e.etag, nil
+		}
+	}
+
+	sum := sha256.New()
+	if _, err := io.Copy(sum, content); err != nil {
+		return "", err
+	}
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	etag := `"` + hex.EncodeToString(sum.Sum(nil)[:16]) + `"`
+	s.etags.Store(name, etagEntry{size: info.Size(), modTime: info.ModTime(), etag: etag})
+	return etag, nil
+}
+
+func (s *staticServer) serveListing(ctx *Context, dir string) {
+	entries, err := fs.ReadDir(s.fsys, dir)
+	if err != nil {
+		s.notFound(ctx)
+		return
+	}
+
+	base := ctx.Request().URL.Path
+	if !strings.HasSuffix(base, "/") {
+		base += "/"
+	}
+
+	var b strings.Builder
+	b.WriteString("<!doctype html>\n<meta charset=\"utf-8\">\n<pre>\n")
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() {
+			name += "/"
+		}
+		u := url.URL{Path: base + name}
+		fmt.Fprintf(&b, "<a href=\"%s\">%s</a>\n", html.EscapeString(u.String()), html.EscapeString(name))
+	}
+	b.WriteString("</pre>\n")
+
+	ctx.ResponseWriter().Header().Set("Content-Type", "text/html; charset=utf-8")
+	ctx.Status(http.StatusOK)
+	io.WriteString(ctx.ResponseWriter(), b.String())
+}
+
+func (s *staticServer) notFound(ctx *Context) {
+	s.router.mu.RLock()
+	notFound := s.router.notFound
+	s.router.mu.RUnlock()
+	notFound(ctx)
+}
+
+// acceptsGzip reports whether the request allows a gzip-encoded response.
+func acceptsGzip(req *http.Request) bool {
+	for _, part := range strings.Split(req.Header.Get("Accept-Encoding"), ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if !strings.EqualFold(strings.TrimSpace(coding), "gzip") {
+			continue
+		}
+		q, ok := strings.CutPrefix(strings.ReplaceAll(params, " ", ""), "q=")
+		if !ok {
+			return true
+		}
+		v, err := strconv.ParseFloat(q, 64)
+		return err == nil && v > 0
+	}
+	return false
+}
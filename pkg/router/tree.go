@@ -0,0 +1,410 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// node is a node of the compressed radix tree that stores the routes.
+//
+// Static text is compressed: a static node holds the longest prefix shared
+// by everything below it, and its static children are indexed by their
+// first byte. A :param node matches one non-empty path segment and a
+// *catch-all node matches the rest of the path. Lookup tries static
+// children first, then :param children (constrained before plain, each in
+// registration order), then the catch-all, backtracking when a branch
+// fails to produce a handler for the method.
+type node struct {
+	// prefix is the static text matched by a static node.
+	prefix string
+	// indices holds the first byte of each static child, in the same order
+	// as children.
+	indices  string
+	children []*node
+	params   []*node
+	catchAll *node
+
+	// segment is the pattern segment of a param or catch-all node, e.g.
+	// ":id<int>" or "*filepath".
+	segment    string
+	paramName  string
+	constraint constraint
+	// constraintExpr is the text between the angle brackets, "" for none.
+	constraintExpr string
+	// origin is the route whose registration created a param or catch-all
+	// node, reported when a later registration conflicts with it.
+	origin *Route
+
+	handlers map[string]*Route
+}
+
+// pathParam is a path parameter captured while matching a request.
+type pathParam struct {
+	key   string
+	value string
+}
+
+// patternPart is a piece of a route pattern: a run of static text or a
+// single :param / *catch-all segment.
+type patternPart struct {
+	static  string
+	segment string
+}
+
+// splitPattern breaks a route pattern into static runs and parameter
+// segments, validating catch-alls along the way.
+func splitPattern(path string) []patternPart {
+	var (
+		parts  []patternPart
+		static strings.Builder
+	)
+	segments := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	for i, segment := range segments {
+		static.WriteByte('/')
+		switch {
+		case strings.HasPrefix(segment, "*"):
+			if i != len(segments)-1 {
+				panic(fmt.Sprintf("router: catch-all %q must be the last segment in %q", segment, path))
+			}
+			if len(segment) == 1 {
+				panic(fmt.Sprintf("router: catch-all in %q must be named, e.g. *filepath", path))
+			}
+			if strings.ContainsAny(segment, "<>") {
+				panic(fmt.Sprintf("router: catch-all %q in %q cannot have a constraint", segment, path))
+			}
+			fallthrough
+		case strings.HasPrefix(segment, ":"):
+			parts = append(parts, patternPart{static: static.String()})
+			static.Reset()
+			parts = append(parts, patternPart{segment: segment})
+		default:
+			static.WriteString(segment)
+		}
+	}
+	if static.Len() > 0 {
+		parts = append(parts, patternPart{static: static.String()})
+	}
+	return parts
+}
+
+// insert stores rt under its path for method. It panics if the method and
+// pattern are already registered, or if a parameter conflicts with one
+// registered at the same position.
+func (n *node) insert(method, path string, rt *Route) {
+	cur := n
+	for _, part := range splitPattern(path) {
+		switch {
+		case part.segment == "":
+			cur = cur.insertStatic(part.static)
+		case part.segment[0] == '*':
+			cur = cur.insertCatchAll(part.segment, rt)
+		default:
+			cur = cur.insertParam(part.segment, rt)
+		}
+	}
+
+	if existing := cur.handlers[method]; existing != nil {
+		panic(fmt.Sprintf("router: duplicate route %s %s at %s, already registered at %s",
+			method, path, rt.source, existing.source))
+	}
+	if cur.handlers == nil {
+		cur.handlers = make(map[string]*Route)
+	}
+	cur.handlers[method] = rt
+}
+
+// insertStatic walks or creates the static nodes spelling s below n,
+// splitting existing nodes where they diverge, and returns the last one.
+func (n *node) insertStatic(s string) *node {
+	for s != "" {
+		i := strings.IndexByte(n.indices, s[0])
+		if i < 0 {
+			child := &node{prefix: s}
+			n.indices += s[:1]
+			n.children = append(n.children, child)
+			return child
+		}
+
+		child := n.children[i]
+		l := commonPrefix(child.prefix, s)
+		if l < len(child.prefix) {
+			// Split child at the divergence point.
+			tail := *child
+			tail.prefix = child.prefix[l:]
+			*child = node{
+				prefix:   child.prefix[:l],
+				indices:  tail.prefix[:1],
+				children: []*node{&tail},
+			}
+		}
+		n, s = child, s[l:]
+	}
+	return n
+}
+
+// insertParam returns the :param child of n for segment, creating it if
+// needed. Constrained params are kept ahead of plain ones. Two params with
+// the same constraint but different names conflict: the second could only
+// ever be reached by backtracking, so its name would be a lie.
+func (n *node) insertParam(segment string, rt *Route) *node {
+	for _, c := range n.params {
+		if c.segment == segment {
+			return c
+		}
+	}
+
+	child := &node{segment: segment, origin: rt}
+	child.paramName, child.constraint = parseParamSegment(segment, rt.path)
+	child.constraintExpr = segment[1+len(child.paramName):]
+
+	for _, c := range n.params {
+		if c.constraintExpr == child.constraintExpr {
+			panic(fmt.Sprintf("router: parameter %q in %s %s at %s conflicts with %q in %s %s at %s",
+				segment, rt.method, rt.path, rt.source,
+				c.segment, c.origin.method, c.origin.path, c.origin.source))
+		}
+	}
+
+	i := len(n.params)
+	if child.constraint != nil {
+		for i = 0; i < len(n.params) && n.params[i].constraint != nil; i++ {
+		}
+	}
+	n.params = append(n.params, nil)
+	copy(n.params[i+1:], n.params[i:])
+	n.params[i] = child
+	return child
+}
+
+// insertCatchAll returns the *catch-all child of n, creating it if needed.
+func (n *node) insertCatchAll(segment string, rt *Route) *node {
+	if c := n.catchAll; c != nil {
+		// Only one catch-all may hang off a node, otherwise it would be
+		// ambiguous which name receives the remaining path.
+		if c.segment != segment {
+			panic(fmt.Sprintf("router: catch-all %q in %s %s at %s conflicts with %q in %s %s at %s",
+				segment, rt.method, rt.path, rt.source,
+				c.segment, c.origin.method, c.origin.path, c.origin.source))
+		}
+		return c
+	}
+	n.catchAll = &node{segment: segment, paramName: segment[1:], origin: rt}
+	return n.catchAll
+}
+
+func commonPrefix(a, b string) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	return i
+}
+
+// find returns the route for method+path, appending captured parameters to
+// params. When raw is set, path is the escaped form of the URL path and
+// parameter values are unescaped, so "a%2Fb" reaches a :param as "a/b".
+func (n *node) find(method, path string, raw bool, params *[]pathParam) routeMatch {
+	var match routeMatch
+	match.route = n.lookup(method, path, raw, params, &match)
+	return match
+}
+
+// lookup matches path against static node n and everything below it.
+func (n *node) lookup(method, path string, raw bool, params *[]pathParam, match *routeMatch) *Route {
+	if !strings.HasPrefix(path, n.prefix) {
+		// A catch-all also matches an empty remainder, so /static/*filepath
+		// serves /static itself.
+		if n.catchAll != nil && len(n.prefix) == len(path)+1 && n.prefix[len(path)] == '/' && n.prefix[:len(path)] == path {
+			return n.catchAll.capture(method, "", raw, params, match)
+		}
+		return nil
+	}
+	return n.lookupBelow(method, path[len(n.prefix):], raw, params, match)
+}
+
+// lookupBelow matches the remaining path against the children of n.
+func (n *node) lookupBelow(method, path string, raw bool, params *[]pathParam, match *routeMatch) *Route {
+	if path == "" {
+		if rt := n.handler(method, match); rt != nil {
+			return rt
+		}
+		if n.catchAll != nil {
+			return n.catchAll.capture(method, "", raw, params, match)
+		}
+		if i := strings.IndexByte(n.indices, '/'); i >= 0 {
+			return n.children[i].lookup(method, path, raw, params, match)
+		}
+		return nil
+	}
+
+	// 1) static children, by first byte
+	if i := strings.IndexByte(n.indices, path[0]); i >= 0 {
+		if rt := n.children[i].lookup(method, path, raw, params, match); rt != nil {
+			return rt
+		}
+	}
+
+	// 2) params, constrained before plain
+	if len(n.params) > 0 {
+		end := strings.IndexByte(path, '/')
+		if end < 0 {
+			end = len(path)
+		}
+		if end > 0 {
+			value := unescape(path[:end], raw)
+			mark := len(*params)
+			for _, child := range n.params {
+				if child.constraint != nil && !child.constraint(value) {
+					continue
+				}
+				*params = append(*params, pathParam{key: child.paramName, value: value})
+				if rt := child.lookupBelow(method, path[end:], raw, params, match); rt != nil {
+					return rt
+				}
+				*params = (*params)[:mark]
+			}
+		}
+	}
+
+	// 3) finally the catch-all swallows the rest of the path
+	if n.catchAll != nil {
+		return n.catchAll.capture(method, path, raw, params, match)
+	}
+	return nil
+}
+
+// capture returns the catch-all's route for method, recording rest as its
+// parameter value.
+func (n *node) capture(method, rest string, raw bool, params *[]pathParam, match *routeMatch) *Route {
+	rt := n.handler(method, match)
+	if rt != nil {
+		*params = append(*params, pathParam{key: n.paramName, value: unescape(rest, raw)})
+	}
+	return rt
+}
+
+// handler returns n's route for method, falling back to GET for HEAD.
+// A node that serves the path but not the method is recorded in match.
+func (n *node) handler(method string, match *routeMatch) *Route {
+	if rt := n.handlers[method]; rt != nil {
+		return rt
+	}
+	if method == http.MethodHead {
+		if rt := n.handlers[http.MethodGet]; rt != nil {
+			return rt
+		}
+	}
+	if len(n.handlers) > 0 {
+		match.candidates = append(match.candidates, n)
+	}
+	return nil
+}
+
+// serves reports whether n has a handler for method, counting GET for HEAD.
+func (n *node) serves(method string) bool {
+	if n.handlers[method] != nil {
+		return true
+	}
+	return method == http.MethodHead && n.handlers[http.MethodGet] != nil
+}
+
+func unescape(s string, raw bool) string {
+	if !raw {
+		return s
+	}
+	if v, err := url.PathUnescape(s); err == nil {
+		return v
+	}
+	return s
+}
+
+// findFold matches path like lookup but compares static text
+// case-insensitively. It returns the canonical path of the match: the
+// request path with the registered spelling of every static part.
+func (n *node) findFold(method, path string, raw bool) (string, bool) {
+	canonical, ok := n.lookupFold(method, path, raw, nil)
+	return string(canonical), ok
+}
+
+func (n *node) lookupFold(method, path string, raw bool, buf []byte) ([]byte, bool) {
+	if len(path) < len(n.prefix) || !strings.EqualFold(path[:len(n.prefix)], n.prefix) {
+		if n.catchAll != nil && n.catchAll.serves(method) && len(n.prefix) == len(path)+1 && strings.EqualFold(path+"/", n.prefix) {
+			return append(buf, n.prefix[:len(path)]...), true
+		}
+		return nil, false
+	}
+	return n.lookupFoldBelow(method, path[len(n.prefix):], raw, append(buf, n.prefix...))
+}
+
+func (n *node) lookupFoldBelow(method, path string, raw bool, buf []byte) ([]byte, bool) {
+	if path == "" {
+		if n.serves(method) || (n.catchAll != nil && n.catchAll.serves(method)) {
+			return buf, true
+		}
+		if i := strings.IndexByte(n.indices, '/'); i >= 0 {
+			return n.children[i].lookupFold(method, path, raw, buf)
+		}
+		return nil, false
+	}
+
+	for i := 0; i < len(n.indices); i++ {
+		if lower(n.indices[i]) != lower(path[0]) {
+			continue
+		}
+		if out, ok := n.children[i].lookupFold(method, path, raw, buf); ok {
+			return out, true
+		}
+	}
+
+	end := strings.IndexByte(path, '/')
+	if end < 0 {
+		end = len(path)
+	}
+	if end > 0 {
+		for _, child := range n.params {
+			if child.constraint != nil && !child.constraint(unescape(path[:end], raw)) {
+				continue
+			}
+			if out, ok := child.lookupFoldBelow(method, path[end:], raw, append(buf, path[:end]...)); ok {
+				return out, true
+			}
+		}
+	}
+
+	if n.catchAll != nil && n.catchAll.serves(method) {
+		return append(buf, path...), true
+	}
+	return nil, false
+}
+
+func lower(c byte) byte {
+	if 'A' <= c && c <= 'Z' {
+		return c + 'a' - 'A'
+	}
+	return c
+}
+
+// walk calls fn for every route stored under n, depth first.
+func (n *node) walk(fn func(*Route)) {
+	methods := make([]string, 0, len(n.handlers))
+	for method := range n.handlers {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	for _, method := range methods {
+		fn(n.handlers[method])
+	}
+
+	for _, c := range n.children {
+		c.walk(fn)
+	}
+	for _, c := range n.params {
+		c.walk(fn)
+	}
+	if n.catchAll != nil {
+		n.catchAll.walk(fn)
+	}
+}
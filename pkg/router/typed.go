@@ -0,0 +1,107 @@
+package router
+
+import (
+	"net/http"
+	"reflect"
+
+	"github.com/alejandrombjs/go-bastion-lib/pkg/validate"
+)
+
+// StatusCoder lets the result of a Typed handler choose its status code.
+type StatusCoder interface {
+	StatusCode() int
+}
+
+// TypedHandler is a handler built by Typed and registered with
+// Router.HandleTyped. The request and response types it carries are
+// reported in RouteInfo for documentation generators.
+type TypedHandler struct {
+	in, out reflect.Type
+	handle  HandlerE
+}
+
+// RequestType returns the type of the handler's input.
+func (h *TypedHandler) RequestType() reflect.Type {
+	return h.in
+}
+
+// ResponseType returns the type of the handler's result.
+func (h *TypedHandler) ResponseType() reflect.Type {
+	return h.out
+}
+
+// Typed turns a function taking a request struct and returning a result
+// into a handler:
+//
+//	type createUser struct {
+//		OrgID int64  `path:"org"`
+//		Name  string `json:"name" validate:"required"`
+//	}
+//
+//	r.HandleTyped(http.MethodPost, "/orgs/:org/users", router.Typed(func(ctx *router.Context, in createUser) (User, error) {
+//		return store.CreateUser(ctx, in.OrgID, in.Name)
+//	}))
+//
+// The input is filled and validated with Context.Bind (In may be a struct
+// or a pointer to one), then fn is called. A non-nil error goes to the
+// router's ErrorHandler, so binding and validation failures produce the
+// usual 4xx responses. The result is written with Context.Negotiate: 201
+// for POST and 200 otherwise, unless it implements StatusCoder. A result
+// of type struct{} is answered with 204 No Content.
+//
+// Typed panics if a validate tag of In names an unknown rule.
+func Typed[In, Out any](fn func(ctx *Context, in In) (Out, error)) *TypedHandler {
+	inType := reflect.TypeOf((*In)(nil)).Elem()
+	outType := reflect.TypeOf((*Out)(nil)).Elem()
+	if err := validate.Check(inType); err != nil {
+		panic("router: " + err.Error())
+	}
+
+	return &TypedHandler{
+		in:  inType,
+		out: outType,
+		handle: func(ctx *Context) error {
+			var in In
+			if err := bindTyped(ctx, &in, inType); err != nil {
+				return err
+			}
+
+			out, err := fn(ctx, in)
+			if err != nil {
+				return err
+			}
+
+			if outType == emptyStructType {
+				ctx.Status(http.StatusNoContent)
+				return nil
+			}
+			status := http.StatusOK
+			if ctx.Request().Method == http.MethodPost {
+				status = http.StatusCreated
+			}
+			if sc, ok := any(out).(StatusCoder); ok {
+				status = sc.StatusCode()
+			}
+			ctx.Negotiate(status, out)
+			return nil
+		},
+	}
+}
+
+var emptyStructType = reflect.TypeOf(struct{}{})
+
+// bindTyped binds the request into *dest, allocating the struct when In
+// is a pointer. Inputs that are not structs are left at their zero value.
+func bindTyped(ctx *Context, dest any, t reflect.Type) error {
+	switch {
+	case t == emptyStructType:
+		return nil
+	case t.Kind() == reflect.Struct:
+		return ctx.Bind(dest)
+	case t.Kind() == reflect.Pointer && t.Elem().Kind() == reflect.Struct:
+		v := reflect.New(t.Elem())
+		reflect.ValueOf(dest).Elem().Set(v)
+		return ctx.Bind(v.Interface())
+	}
+	return nil
+}
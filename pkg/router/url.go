@@ -0,0 +1,84 @@
+package router
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// URL builds the path of the route registered under name, substituting
+// params given as alternating key/value pairs:
+//
+//	r.URL("users.show", "id", 42) // "/users/42"
+//
+// Values are formatted with fmt.Sprint and path-escaped. Pairs that do not
+// name a path parameter are appended as a query string. An error is
+// returned for unknown route names, missing parameters and values that
+// violate the parameter's constraint.
+func (r *Router) URL(name string, params ...any) (string, error) {
+	root := r.root()
+	root.mu.RLock()
+	rt := root.names[name]
+	root.mu.RUnlock()
+	if rt == nil {
+		return "", fmt.Errorf("router: no route named %q", name)
+	}
+
+	if len(params)%2 != 0 {
+		return "", fmt.Errorf("router: URL(%q) needs key/value pairs, got %d arguments", name, len(params))
+	}
+	values := make(map[string]string, len(params)/2)
+	for i := 0; i < len(params); i += 2 {
+		key, ok := params[i].(string)
+		if !ok {
+			return "", fmt.Errorf("router: URL(%q) parameter key %v is not a string", name, params[i])
+		}
+		values[key] = fmt.Sprint(params[i+1])
+	}
+
+	segments := strings.Split(strings.TrimPrefix(rt.path, "/"), "/")
+	for i, segment := range segments {
+		switch {
+		case strings.HasPrefix(segment, ":"):
+			key, check := parseParamSegment(segment, rt.path)
+			v, ok := values[key]
+			if !ok {
+				return "", fmt.Errorf("router: URL(%q) missing parameter %q", name, key)
+			}
+			if check != nil && !check(v) {
+				return "", fmt.Errorf("router: URL(%q) parameter %q value %q violates constraint in %q", name, key, v, rt.path)
+			}
+			segments[i] = url.PathEscape(v)
+			delete(values, key)
+
+		case strings.HasPrefix(segment, "*"):
+			key := segment[1:]
+			v, ok := values[key]
+			if !ok {
+				return "", fmt.Errorf("router: URL(%q) missing parameter %q", name, key)
+			}
+			parts := strings.Split(strings.TrimPrefix(v, "/"), "/")
+			for j := range parts {
+				parts[j] = url.PathEscape(parts[j])
+			}
+			segments[i] = strings.Join(parts, "/")
+			delete(values, key)
+		}
+	}
+
+	u := "/" + strings.Join(segments, "/")
+	if len(values) > 0 {
+		keys := make([]string, 0, len(values))
+		for k := range values {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		q := url.Values{}
+		for _, k := range keys {
+			q.Set(k, values[k])
+		}
+		u += "?" + q.Encode()
+	}
+	return u, nil
+}
@@ -0,0 +1,273 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// versionParam is the catch-all that receives the path below a Versions prefix.
+const versionParam = "versionpath"
+
+// VersionStrategy selects where the requested API version is read from.
+type VersionStrategy int
+
+const (
+	// VersionByPath reads the version from the first segment below the
+	// prefix, e.g. /api/v2/users.
+	VersionByPath VersionStrategy = iota
+	// VersionByHeader reads the version from a request header.
+	VersionByHeader
+	// VersionByMediaType reads the version from the Accept header, either
+	// as a vendor media type (application/vnd.acme.v2+json) or as a
+	// version parameter (application/json; version=2).
+	VersionByMediaType
+)
+
+// VersionConfig configures Router.Versions.
+type VersionConfig struct {
+	Strategy VersionStrategy
+	// Header is the request header read by VersionByHeader.
+	// Defaults to "X-API-Version".
+	Header string
+	// Vendor is the vendor name matched by VersionByMediaType, e.g. "acme"
+	// for application/vnd.acme.v2+json.
+	Vendor string
+	// Default is the version served when the request names none. Defaults
+	// to the first version declared.
+	Default string
+}
+
+// Deprecation describes a deprecated API version. Requests served by it
+// carry Deprecation and, when set, Sunset (RFC 8594) and Link headers.
+type Deprecation struct {
+	// At is when the version was deprecated; zero sends "Deprecation: true".
+	At time.Time
+	// Sunset is when the version stops being served.
+	Sunset time.Time
+	// Link points to migration documentation.
+	Link string
+}
+
+// Versions dispatches requests below a prefix to one of several route sets
+// according to the requested API version. Requests for a version that
+// doesn't exist get 406 Not Acceptable. The selected version is stored in
+// the Context under "apiVersion".
+type Versions struct {
+	router   *Router
+	prefix   string
+	cfg      VersionConfig
+	order    []string
+	versions map[string]*apiVersion
+}
+
+type apiVersion struct {
+	name        string
+	router      *Router
+	deprecation *Deprecation
+}
+
+// Versions installs API versioning below prefix:
+//
+//	api := r.Versions("/api", router.VersionConfig{Strategy: router.VersionByMediaType, Vendor: "acme"})
+//	api.Version("1").GET("/users", listUsersV1)
+//	api.Version("2").GET("/users", listUsersV2)
+//	api.Deprecate("1", router.Deprecation{Sunset: sunset})
+//
+// Middleware of r and its ancestors runs before the version is selected;
+// middleware added to a version router runs after.
+func (r *Router) Versions(prefix string, cfg VersionConfig) *Versions {
+	if cfg.Header == "" {
+		cfg.Header = "X-API-Version"
+	}
+	prefix = strings.TrimSuffix(prefix, "/")
+	v := &Versions{
+		router:   r,
+		prefix:   prefix,
+		cfg:      cfg,
+		versions: make(map[string]*apiVersion),
+	}
+	// The dispatcher is plumbing; documentation lists the version routes.
+	for _, rt := range r.Any(prefix+"/*"+versionParam, v.serve) {
+		rt.Meta("hidden", true)
+	}
+	return v
+}
+
+// Version returns the router holding the routes of version name, creating
+// it on first use. With VersionByPath its routes live below
+// "<prefix>/v<name>".
+func (v *Versions) Version(name string) *Router {
+	root := v.router.root()
+	root.mu.Lock()
+	defer root.mu.Unlock()
+
+	if ver := v.versions[name]; ver != nil {
+		return ver.router
+	}
+
+	prefix := v.router.prefix + v.prefix
+	if v.cfg.Strategy == VersionByPath {
+		prefix += "/v" + name
+	}
+	sub := &Router{
+		prefix:           prefix,
+		host:             v.router.host,
+		version:          name,
+		parent:           v.router,
+		tree:             &node{},
+		notFound:         v.router.notFound,
+		methodNotAllowed: v.router.methodNotAllowed,
+	}
+	v.versions[name] = &apiVersion{name: name, router: sub}
+	v.order = append(v.order, name)
+	if v.cfg.Default == "" {
+		v.cfg.Default = name
+	}
+	root.versioned = append(root.versioned, sub)
+	return sub
+}
+
+// Deprecate marks version name as deprecated.
+func (v *Versions) Deprecate(name string, d Deprecation) {
+	v.Version(name)
+
+	root := v.router.root()
+	root.mu.Lock()
+	defer root.mu.Unlock()
+	v.versions[name].deprecation = &d
+}
+
+// serve selects the version for the request and dispatches to its routes.
+func (v *Versions) serve(ctx *Context) {
+	req := ctx.Request()
+	rest := ctx.Param(versionParam)
+	// The catch-all is the last captured param; the version routes capture
+	// their own.
+	ctx.params = ctx.params[:len(ctx.params)-1]
+
+	root := v.router.root()
+	root.mu.RLock()
+	name, lookupPath, ok := v.selectVersion(req, rest)
+	ver := v.versions[name]
+	root.mu.RUnlock()
+
+	switch v.cfg.Strategy {
+	case VersionByHeader:
+		ctx.ResponseWriter().Header().Add("Vary", v.cfg.Header)
+	case VersionByMediaType:
+		ctx.ResponseWriter().Header().Add("Vary", "Accept")
+	}
+	if !ok || ver == nil {
+		ctx.JSON(http.StatusNotAcceptable, map[string]string{
+			"error": "Not Acceptable",
+		})
+		return
+	}
+
+	ctx.Set("apiVersion", ver.name)
+	if d := ver.deprecation; d != nil {
+		setDeprecationHeaders(ctx.ResponseWriter().Header(), d)
+	}
+
+	raw := false
+	if lookupPath == "" {
+		lookupPath, raw = requestPath(req.URL)
+	}
+
+	root.mu.RLock()
+	match := ver.router.tree.find(req.Method, lookupPath, raw, &ctx.params)
+	if match.route != nil {
+		match.handler = match.route.chain
+	}
+	notFound, methodNotAllowed := root.notFound, root.methodNotAllowed
+	root.mu.RUnlock()
+
+	if match.route == nil {
+		if len(match.candidates) == 0 {
+			notFound(ctx)
+			return
+		}
+		ctx.ResponseWriter().Header().Set("Allow", strings.Join(match.allowedMethods(), ", "))
+		if req.Method == http.MethodOptions {
+			ctx.Status(http.StatusNoContent)
+			return
+		}
+		methodNotAllowed(ctx)
+		return
+	}
+
+	ctx.route = match.route
+	match.handler(ctx)
+}
+
+// selectVersion returns the requested version and, for VersionByPath, the
+// path to look up in its tree. ok is false when the request names a
+// version in a recognisable form that is not declared. The caller holds
+// the router lock.
+func (v *Versions) selectVersion(req *http.Request, rest string) (name, lookupPath string, ok bool) {
+	switch v.cfg.Strategy {
+	case VersionByHeader:
+		name = strings.TrimSpace(req.Header.Get(v.cfg.Header))
+
+	case VersionByMediaType:
+		name = v.mediaTypeVersion(req.Header.Get("Accept"))
+
+	default:
+		first, tail, _ := strings.Cut(rest, "/")
+		if len(first) > 1 && first[0] == 'v' && first[1] >= '0' && first[1] <= '9' {
+			name = first[1:]
+			rest = tail
+		}
+		if name == "" {
+			name = v.cfg.Default
+		}
+		if ver := v.versions[name]; ver != nil {
+			lookupPath = ver.router.prefix + "/" + rest
+		}
+	}
+
+	if name == "" {
+		name = v.cfg.Default
+	}
+	_, ok = v.versions[name]
+	return name, lookupPath, ok
+}
+
+// mediaTypeVersion extracts the version from an Accept header, looking at
+// media ranges in order for application/vnd.<vendor>.v<N>+<suffix> or a
+// version=<N> parameter.
+func (v *Versions) mediaTypeVersion(accept string) string {
+	vendor := "application/vnd." + strings.ToLower(v.cfg.Vendor) + ".v"
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, _ := strings.Cut(part, ";")
+		mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+
+		if v.cfg.Vendor != "" && strings.HasPrefix(mediaType, vendor) {
+			version, _, _ := strings.Cut(mediaType[len(vendor):], "+")
+			return version
+		}
+		for _, param := range strings.Split(params, ";") {
+			key, value, _ := strings.Cut(param, "=")
+			if strings.EqualFold(strings.TrimSpace(key), "version") {
+				return strings.Trim(strings.TrimSpace(value), `"`)
+			}
+		}
+	}
+	return ""
+}
+
+func setDeprecationHeaders(h http.Header, d *Deprecation) {
+	if d.At.IsZero() {
+		h.Set("Deprecation", "true")
+	} else {
+		h.Set("Deprecation", fmt.Sprintf("@%d", d.At.Unix()))
+	}
+	if !d.Sunset.IsZero() {
+		h.Set("Sunset", d.Sunset.UTC().Format(http.TimeFormat))
+	}
+	if d.Link != "" {
+		h.Add("Link", fmt.Sprintf("<%s>; rel=\"deprecation\"", d.Link))
+	}
+}
@@ -0,0 +1,421 @@
+// Package validate checks struct fields against rules declared in
+// `validate:"..."` tags:
+//
+//	type createUser struct {
+//		Name  string   `json:"name" validate:"required,max=100"`
+//		Email string   `json:"email" validate:"required,email"`
+//		Age   int      `json:"age" validate:"min=1,max=120"`
+//		Role  string   `json:"role" validate:"omitempty,oneof=admin member"`
+//		Tags  []string `json:"tags" validate:"max=10"`
+//	}
+//
+// Rules are separated by commas; a rule's parameter follows "=".
+// "required" rejects zero values and empty slices and maps; "omitempty"
+// skips the remaining rules when the value is empty. Custom rules are
+// added with Register. Nested structs, pointers to structs and slices or
+// maps of structs are validated recursively. Every failing field is
+// reported, not just the first.
+package validate
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Rule reports whether value satisfies the rule given its parameter, the
+// text after "=" in the tag ("" when there is none). Pointers are
+// dereferenced before rules run, except for "required".
+type Rule func(value reflect.Value, param string) bool
+
+// FieldError describes one field that failed a rule.
+type FieldError struct {
+	// Field is the path of the field as the client sent it, using json
+	// (or binding) tag names, e.g. "items[2].sku".
+	Field string `json:"field"`
+	// Code is the name of the failing rule, e.g. "required" or "max".
+	Code string `json:"code"`
+	// Param is the rule's parameter, e.g. "120" for max=120.
+	Param string `json:"param,omitempty"`
+	// Message is a human-readable description.
+	Message string `json:"message"`
+}
+
+func (e FieldError) Error() string {
+	return e.Message
+}
+
+// Errors lists every field that failed validation.
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, fe := range e {
+		msgs[i] = fe.Message
+	}
+	return "validation failed: " + strings.Join(msgs, "; ")
+}
+
+var (
+	mu    sync.RWMutex
+	rules = map[string]Rule{
+		"min":      ruleMin,
+		"max":      ruleMax,
+		"len":      ruleLen,
+		"email":    ruleEmail,
+		"url":      ruleURL,
+		"uuid":     ruleRegexp(regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)),
+		"alpha":    ruleRegexp(regexp.MustCompile(`^[a-zA-Z]+$`)),
+		"alphanum": ruleRegexp(regexp.MustCompile(`^[a-zA-Z0-9]+$`)),
+		"oneof":    ruleOneOf,
+	}
+	messages = map[string]string{
+		"required": "%s is required",
+		"min":      "%s must be at least %s",
+		"max":      "%s must be at most %s",
+		"len":      "%s must have length %s",
+		"email":    "%s must be a valid email address",
+		"url":      "%s must be a valid URL",
+		"uuid":     "%s must be a valid UUID",
+		"alpha":    "%s must contain only letters",
+		"alphanum": "%s must contain only letters and digits",
+		"oneof":    "%s must be one of: %s",
+	}
+)
+
+// Register adds a custom rule usable in validate tags. message is a
+// format string receiving the field path and the rule parameter, e.g.
+// "%s must be a multiple of %s". Register panics if name is empty,
+// reserved ("required", "omitempty") or already registered.
+func Register(name string, rule Rule, message string) {
+	mu.Lock()
+	defer mu.Unlock()
+	if name == "" || name == "required" || name == "omitempty" {
+		panic(fmt.Sprintf("validate: cannot register rule %q", name))
+	}
+	if _, exists := rules[name]; exists {
+		panic(fmt.Sprintf("validate: rule %q already registered", name))
+	}
+	rules[name] = rule
+	messages[name] = message
+}
+
+// Struct validates v, a struct or pointer to a struct. It returns nil or
+// an Errors value listing every failing field.
+func Struct(v any) error {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("validate: expected a struct, got %T", v)
+	}
+
+	var errs Errors
+	if err := validateStruct(rv, "", &errs); err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Check reports a validate tag naming an unknown rule in t or in a struct
+// it contains. t may be a struct type or a pointer, slice or map of one.
+// Struct returns the same error, so calling Check at startup surfaces a
+// misspelt tag before the first request does.
+func Check(t reflect.Type) error {
+	return checkType(t, make(map[reflect.Type]bool))
+}
+
+func checkType(t reflect.Type, seen map[reflect.Type]bool) error {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || seen[t] {
+		return nil
+	}
+	seen[t] = true
+
+	fields, err := rulesOf(t)
+	if err != nil {
+		return err
+	}
+	for _, fr := range fields {
+		if err := checkType(t.Field(fr.index).Type, seen); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fieldRules is the parsed validate tag of one struct field.
+type fieldRules struct {
+	index     int
+	name      string
+	required  bool
+	omitempty bool
+	rules     []tagRule
+}
+
+type tagRule struct {
+	name  string
+	param string
+}
+
+var structCache sync.Map // reflect.Type -> []fieldRules
+
+// rulesOf parses the validate tags of t. Only valid tags are cached, so a
+// rule registered later makes a failing type usable.
+func rulesOf(t reflect.Type) ([]fieldRules, error) {
+	if v, ok := structCache.Load(t); ok {
+		return v.([]fieldRules), nil
+	}
+
+	var fields []fieldRules
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		fr := fieldRules{index: i, name: fieldName(sf)}
+		tag := sf.Tag.Get("validate")
+		if tag == "-" {
+			continue
+		}
+		for _, part := range strings.Split(tag, ",") {
+			part = strings.TrimSpace(part)
+			name, param, _ := strings.Cut(part, "=")
+			switch name {
+			case "":
+			case "required":
+				fr.required = true
+			case "omitempty":
+				fr.omitempty = true
+			default:
+				mu.RLock()
+				_, ok := rules[name]
+				mu.RUnlock()
+				if !ok {
+					return nil, fmt.Errorf("validate: unknown rule %q on %s.%s", name, t, sf.Name)
+				}
+				fr.rules = append(fr.rules, tagRule{name: name, param: param})
+			}
+		}
+		if sf.Anonymous && fr.name == sf.Name {
+			// Untagged embedded structs are flattened into the parent.
+			fr.name = ""
+		}
+		fields = append(fields, fr)
+	}
+
+	structCache.Store(t, fields)
+	return fields, nil
+}
+
+// fieldName returns the name clients know a field by: its json tag, else
+// a binding tag, else the Go name.
+func fieldName(sf reflect.StructField) string {
+	for _, tag := range []string{"json", "query", "path", "form", "header", "cookie"} {
+		name, _, _ := strings.Cut(sf.Tag.Get(tag), ",")
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+	return sf.Name
+}
+
+func validateStruct(rv reflect.Value, prefix string, errs *Errors) error {
+	fields, err := rulesOf(rv.Type())
+	if err != nil {
+		return err
+	}
+	for _, fr := range fields {
+		fv := rv.Field(fr.index)
+		path := prefix
+		if fr.name != "" {
+			if path != "" {
+				path += "."
+			}
+			path += fr.name
+		}
+
+		if isEmpty(fv) {
+			if fr.required {
+				*errs = append(*errs, newFieldError(path, "required", ""))
+				continue
+			}
+			if fr.omitempty {
+				continue
+			}
+		}
+
+		elem := fv
+		for elem.Kind() == reflect.Pointer {
+			if elem.IsNil() {
+				break
+			}
+			elem = elem.Elem()
+		}
+		if elem.Kind() == reflect.Pointer {
+			// A nil optional pointer has nothing to check.
+			continue
+		}
+
+		for _, r := range fr.rules {
+			mu.RLock()
+			rule := rules[r.name]
+			mu.RUnlock()
+			if !rule(elem, r.param) {
+				*errs = append(*errs, newFieldError(path, r.name, r.param))
+			}
+		}
+
+		if err := validateNested(elem, path, errs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateNested descends into structs and into the elements of slices,
+// arrays and maps.
+func validateNested(v reflect.Value, path string, errs *Errors) error {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if !v.IsNil() {
+			return validateNested(v.Elem(), path, errs)
+		}
+	case reflect.Struct:
+		return validateStruct(v, path, errs)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := validateNested(v.Index(i), fmt.Sprintf("%s[%d]", path, i), errs); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			if err := validateNested(iter.Value(), fmt.Sprintf("%s[%v]", path, iter.Key()), errs); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// isEmpty reports whether v fails "required": the zero value, or an empty
+// slice or map.
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
+
+func newFieldError(path, code, param string) FieldError {
+	mu.RLock()
+	format := messages[code]
+	mu.RUnlock()
+
+	var msg string
+	if strings.Count(format, "%s") >= 2 {
+		msg = fmt.Sprintf(format, path, param)
+	} else {
+		msg = fmt.Sprintf(format, path)
+	}
+	return FieldError{Field: path, Code: code, Param: param, Message: msg}
+}
+
+// size returns the number compared by min, max and len: the value of a
+// number, the rune count of a string and the length of a collection.
+func size(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	case reflect.String:
+		return float64(len([]rune(v.String()))), true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(v.Len()), true
+	}
+	return 0, false
+}
+
+func compare(v reflect.Value, param string, ok func(n, limit float64) bool) bool {
+	limit, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return false
+	}
+	n, valid := size(v)
+	return valid && ok(n, limit)
+}
+
+func ruleMin(v reflect.Value, param string) bool {
+	return compare(v, param, func(n, limit float64) bool { return n >= limit })
+}
+
+func ruleMax(v reflect.Value, param string) bool {
+	return compare(v, param, func(n, limit float64) bool { return n <= limit })
+}
+
+func ruleLen(v reflect.Value, param string) bool {
+	return compare(v, param, func(n, limit float64) bool { return n == limit })
+}
+
+func ruleEmail(v reflect.Value, _ string) bool {
+	if v.Kind() != reflect.String {
+		return false
+	}
+	addr, err := mail.ParseAddress(v.String())
+	return err == nil && addr.Address == v.String()
+}
+
+func ruleURL(v reflect.Value, _ string) bool {
+	if v.Kind() != reflect.String {
+		return false
+	}
+	u, err := url.ParseRequestURI(v.String())
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
+
+func ruleRegexp(re *regexp.Regexp) Rule {
+	return func(v reflect.Value, _ string) bool {
+		return v.Kind() == reflect.String && re.MatchString(v.String())
+	}
+}
+
+// ruleOneOf checks the value against a space-separated list of options.
+func ruleOneOf(v reflect.Value, param string) bool {
+	var s string
+	switch v.Kind() {
+	case reflect.String:
+		s = v.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		s = strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		s = strconv.FormatUint(v.Uint(), 10)
+	default:
+		return false
+	}
+	for _, option := range strings.Fields(param) {
+		if s == option {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alejandrombjs/go-bastion-lib/pkg/router"
+)
+
+func TestRouterMount(t *testing.T) {
+	r := router.New()
+
+	var ran bool
+	admin := r.Group("/admin")
+	admin.Use(func(next router.Handler) router.Handler {
+		return func(ctx *router.Context) {
+			ran = true
+			next(ctx)
+		}
+	})
+	admin.Mount("/debug", http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte(req.Method + " " + req.URL.Path))
+	}))
+
+	cases := map[string]string{
+		"/admin/debug/pprof/heap": "GET /pprof/heap",
+		"/admin/debug":            "GET /",
+	}
+	for path, want := range cases {
+		ran = false
+		req := httptest.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		r.Handler().ServeHTTP(w, req)
+
+		if w.Body.String() != want {
+			t.Errorf("%s: expected body %q, got %q", path, want, w.Body.String())
+		}
+		if !ran {
+			t.Errorf("%s: expected group middleware to run", path)
+		}
+	}
+}
+
+func TestRouterWrapM(t *testing.T) {
+	r := router.New()
+
+	r.Use(router.WrapM(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.Header().Set("X-Std", "yes")
+			req.Header.Set("X-Seen", "std")
+			next.ServeHTTP(w, req)
+		})
+	}))
+	r.GET("/", router.WrapF(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte(req.Header.Get("X-Seen")))
+	}))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	r.Handler().ServeHTTP(w, req)
+
+	if w.Header().Get("X-Std") != "yes" || w.Body.String() != "std" {
+		t.Errorf("Expected std middleware to wrap handler, got header %q body %q", w.Header().Get("X-Std"), w.Body.String())
+	}
+}
@@ -0,0 +1,137 @@
+package tests
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alejandrombjs/go-bastion-lib/pkg/router"
+)
+
+type bindPaging struct {
+	Page  int  `query:"page"`
+	Limit *int `query:"limit"`
+}
+
+type bindRequest struct {
+	ID       int64         `path:"id"`
+	Tenant   string        `header:"X-Tenant"`
+	Session  string        `cookie:"sid"`
+	Tags     []string      `query:"tag"`
+	Since    time.Time     `query:"since"`
+	Timeout  time.Duration `query:"timeout"`
+	Verbose  bool          `query:"verbose"`
+	Addr     netip.Addr    `query:"addr"`
+	Name     string        `json:"name"`
+	Nickname string        `form:"nickname"`
+	Paging   bindPaging
+}
+
+func TestBind(t *testing.T) {
+	var got bindRequest
+	r := router.New()
+	r.POST("/users/:id", func(ctx *router.Context) {
+		if err := ctx.Bind(&got); err != nil {
+			t.Fatalf("Bind: %v", err)
+		}
+		ctx.Status(http.StatusNoContent)
+	})
+
+	q := "tag=a&tag=b&since=2024-03-01&timeout=1m30s&verbose=true&addr=10.0.0.1&page=3&limit=20"
+	req := httptest.NewRequest("POST", "/users/42?"+q, strings.NewReader(`{"name":"Ada"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Tenant", "acme")
+	req.AddCookie(&http.Cookie{Name: "sid", Value: "s3cr3t"})
+	r.Handler().ServeHTTP(httptest.NewRecorder(), req)
+
+	if got.ID != 42 || got.Tenant != "acme" || got.Session != "s3cr3t" || got.Name != "Ada" {
+		t.Errorf("Unexpected scalar fields: %+v", got)
+	}
+	if strings.Join(got.Tags, ",") != "a,b" || !got.Verbose || got.Timeout != 90*time.Second {
+		t.Errorf("Unexpected query fields: %+v", got)
+	}
+	if !got.Since.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)) || got.Addr.String() != "10.0.0.1" {
+		t.Errorf("Unexpected since/addr: %v %v", got.Since, got.Addr)
+	}
+	if got.Paging.Page != 3 || got.Paging.Limit == nil || *got.Paging.Limit != 20 {
+		t.Errorf("Unexpected nested struct: %+v", got.Paging)
+	}
+}
+
+func TestBindForm(t *testing.T) {
+	var got bindRequest
+	r := router.New()
+	r.POST("/users/:id", func(ctx *router.Context) {
+		if err := ctx.Bind(&got); err != nil {
+			t.Fatalf("Bind: %v", err)
+		}
+	})
+
+	form := url.Values{"nickname": {"ada"}}
+	req := httptest.NewRequest("POST", "/users/1", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.Handler().ServeHTTP(httptest.NewRecorder(), req)
+
+	if got.Nickname != "ada" || got.ID != 1 {
+		t.Errorf("Unexpected form binding: %+v", got)
+	}
+}
+
+func TestBindError(t *testing.T) {
+	var bindErr *router.BindError
+	r := router.New()
+	r.GET("/users/:id", func(ctx *router.Context) {
+		var req bindRequest
+		err := ctx.Bind(&req)
+		if !errors.As(err, &bindErr) {
+			t.Fatalf("Expected *router.BindError, got %v", err)
+		}
+	})
+
+	req := httptest.NewRequest("GET", "/users/7?page=two", nil)
+	r.Handler().ServeHTTP(httptest.NewRecorder(), req)
+
+	if bindErr == nil || bindErr.Field != "Paging.Page" || bindErr.Source != "query" || bindErr.Key != "page" || bindErr.Value != "two" {
+		t.Errorf("Unexpected error: %+v", bindErr)
+	}
+}
+
+func TestBindJSONWithoutJSONContentType(t *testing.T) {
+	var (
+		got bindRequest
+		err error
+	)
+	r := router.New()
+	r.POST("/users/:id", func(ctx *router.Context) {
+		got = bindRequest{}
+		err = ctx.Bind(&got)
+	})
+	strict := r.Group("/strict")
+	strict.POST("/users/:id", func(ctx *router.Context) {
+		err = ctx.Bind(&bindRequest{})
+	}).JSONOptions(router.JSONOptions{RequireContentType: true})
+
+	for _, contentType := range []string{"", "text/plain"} {
+		req := httptest.NewRequest("POST", "/users/1", strings.NewReader(`{"name":"Ada"}`))
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		r.Handler().ServeHTTP(httptest.NewRecorder(), req)
+		if err != nil || got.Name != "Ada" {
+			t.Errorf("Content-Type %q: got %+v, err %v", contentType, got, err)
+		}
+	}
+
+	req := httptest.NewRequest("POST", "/strict/users/1", strings.NewReader(`{"name":"Ada"}`))
+	req.Header.Set("Content-Type", "text/plain")
+	r.Handler().ServeHTTP(httptest.NewRecorder(), req)
+	var jsonErr *router.JSONError
+	if !errors.As(err, &jsonErr) || jsonErr.Status != http.StatusUnsupportedMediaType {
+		t.Errorf("RequireContentType: expected a 415 *router.JSONError, got %v", err)
+	}
+}
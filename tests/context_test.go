@@ -0,0 +1,106 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alejandrombjs/go-bastion-lib/pkg/router"
+)
+
+type ctxKey struct{}
+
+func TestContextImplementsContext(t *testing.T) {
+	r := router.New()
+	r.Use(func(next router.Handler) router.Handler {
+		return func(ctx *router.Context) {
+			ctx.Set("tenant", "acme")
+			ctx.WithValue(ctxKey{}, "trace-1")
+			next(ctx)
+		}
+	})
+
+	var (
+		tenant, trace, fromRequest any
+		deadline                   time.Time
+		hasDeadline                bool
+		err                        error
+	)
+	r.GET("/", func(ctx *router.Context) {
+		var c context.Context = ctx
+		tenant = c.Value("tenant")
+		trace = c.Value(ctxKey{})
+		fromRequest = ctx.Request().Context().Value(ctxKey{})
+		deadline, hasDeadline = c.Deadline()
+		<-c.Done()
+		err = c.Err()
+	})
+
+	reqCtx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	req := httptest.NewRequest("GET", "/", nil).WithContext(reqCtx)
+	r.Handler().ServeHTTP(httptest.NewRecorder(), req)
+
+	if tenant != "acme" || trace != "trace-1" || fromRequest != "trace-1" {
+		t.Errorf("Unexpected values: tenant=%v trace=%v fromRequest=%v", tenant, trace, fromRequest)
+	}
+	if !hasDeadline || deadline.IsZero() {
+		t.Error("Expected the request deadline")
+	}
+	if err != context.DeadlineExceeded {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestContextSetRequest(t *testing.T) {
+	r := router.New()
+	r.Use(func(next router.Handler) router.Handler {
+		return func(ctx *router.Context) {
+			ctx.Set("user", "ada")
+			req := ctx.Request()
+			ctx.SetRequest(req.WithContext(context.WithValue(req.Context(), ctxKey{}, "swapped")))
+			next(ctx)
+		}
+	})
+
+	var user, value any
+	r.GET("/", router.WrapH(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		value = req.Context().Value(ctxKey{})
+	})), func(next router.Handler) router.Handler {
+		return func(ctx *router.Context) {
+			user = ctx.Value("user")
+			next(ctx)
+		}
+	})
+
+	r.Handler().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	if user != "ada" || value != "swapped" {
+		t.Errorf("Expected user ada and swapped request, got %v %v", user, value)
+	}
+}
+
+func TestContextNotRecycledOnceUsedAsContext(t *testing.T) {
+	r := router.New()
+	var kept []context.Context
+	r.GET("/u/:name", func(ctx *router.Context) {
+		ctx.Set("user", ctx.Param("name"))
+		// WithTimeout watches ctx.Done, which marks ctx as escaped.
+		derived, cancel := context.WithTimeout(ctx, time.Minute)
+		_ = cancel
+		kept = append(kept, derived)
+	})
+	h := r.Handler()
+
+	for _, name := range []string{"alice", "mallory"} {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/u/"+name, nil))
+	}
+
+	if got := kept[0].Value("user"); got != "alice" {
+		t.Errorf("first request's context: Value(user) = %v after the next request, want alice", got)
+	}
+	if got := kept[1].Value("user"); got != "mallory" {
+		t.Errorf("second request's context: Value(user) = %v, want mallory", got)
+	}
+}
@@ -0,0 +1,143 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alejandrombjs/go-bastion-lib/pkg/middleware"
+	"github.com/alejandrombjs/go-bastion-lib/pkg/router"
+)
+
+func newErrorRouter(logs *bytes.Buffer, development bool) *router.Router {
+	r := router.New()
+	r.Use(middleware.RequestID())
+	r.SetErrorHandler(router.NewErrorHandler(router.ErrorHandlerConfig{
+		Logger:      log.New(logs, "", 0),
+		Development: development,
+	}))
+
+	r.GET("/users/:id", router.E(func(ctx *router.Context) error {
+		return router.NewHTTPError(http.StatusNotFound, "user_not_found", "User not found").
+			WithDetails(map[string]string{"id": ctx.Param("id")})
+	}))
+	r.GET("/db", router.E(func(ctx *router.Context) error {
+		return errors.New("connection refused: 10.0.0.5:5432")
+	}))
+	r.POST("/items", router.E(func(ctx *router.Context) error {
+		var req struct {
+			Name string `json:"name" validate:"required"`
+		}
+		return ctx.Bind(&req)
+	}))
+	r.GET("/ok", router.E(router.HandlerE(func(ctx *router.Context) error {
+		ctx.JSON(http.StatusOK, map[string]bool{"ok": true})
+		return nil
+	})))
+	return r
+}
+
+type errorBody struct {
+	Error struct {
+		Code      string          `json:"code"`
+		Message   string          `json:"message"`
+		Details   json.RawMessage `json:"details"`
+		Debug     string          `json:"debug"`
+		RequestID string          `json:"request_id"`
+	} `json:"error"`
+}
+
+func TestHandlerEErrors(t *testing.T) {
+	var logs bytes.Buffer
+	r := newErrorRouter(&logs, false)
+
+	cases := []struct {
+		method, path, body string
+		status             int
+		code, message      string
+		details            string
+	}{
+		{"GET", "/users/7", "", 404, "user_not_found", "User not found", `{"id":"7"}`},
+		{"GET", "/db", "", 500, "internal_error", "Internal Server Error", ""},
+		{"POST", "/items", `{"name":""}`, 422, "validation_failed", "Request validation failed", `[{"field":"name","code":"required","message":"name is required"}]`},
+		{"POST", "/items", `{"name":`, 400, "invalid_json", "", ""},
+	}
+	for _, tc := range cases {
+		logs.Reset()
+		req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.Handler().ServeHTTP(w, req)
+
+		var body errorBody
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("%s: invalid JSON %q", tc.path, w.Body.String())
+		}
+		if w.Code != tc.status || body.Error.Code != tc.code {
+			t.Errorf("%s %s: expected %d %s, got %d %s", tc.method, tc.path, tc.status, tc.code, w.Code, body.Error.Code)
+		}
+		if tc.message != "" && body.Error.Message != tc.message {
+			t.Errorf("%s: expected message %q, got %q", tc.path, tc.message, body.Error.Message)
+		}
+		if string(body.Error.Details) != tc.details {
+			t.Errorf("%s: expected details %s, got %s", tc.path, tc.details, body.Error.Details)
+		}
+		if body.Error.Debug != "" || strings.Contains(w.Body.String(), "10.0.0.5") {
+			t.Errorf("%s: internal details leaked: %s", tc.path, w.Body.String())
+		}
+
+		requestID := w.Header().Get("X-Request-ID")
+		if body.Error.RequestID != requestID || !strings.Contains(logs.String(), "["+requestID+"]") {
+			t.Errorf("%s: expected request ID %s in body and log %q", tc.path, requestID, logs.String())
+		}
+	}
+
+	w := httptest.NewRecorder()
+	r.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/ok", nil))
+	if w.Code != 200 {
+		t.Errorf("Expected 200 from HandlerE, got %d", w.Code)
+	}
+}
+
+func TestErrorHandlerDevelopmentAndHTML(t *testing.T) {
+	var logs bytes.Buffer
+	r := newErrorRouter(&logs, true)
+
+	w := httptest.NewRecorder()
+	r.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/db", nil))
+	var body errorBody
+	json.Unmarshal(w.Body.Bytes(), &body)
+	if !strings.Contains(body.Error.Debug, "connection refused") {
+		t.Errorf("Expected the cause in development, got %s", w.Body.String())
+	}
+
+	req := httptest.NewRequest("GET", "/users/<b>", nil)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,*/*;q=0.8")
+	w = httptest.NewRecorder()
+	r.Handler().ServeHTTP(w, req)
+	if w.Code != 404 || w.Header().Get("Content-Type") != "text/html; charset=utf-8" || !strings.Contains(w.Body.String(), "<h1>404 Not Found</h1>") {
+		t.Errorf("Expected an HTML 404 page, got %d %s %s", w.Code, w.Header().Get("Content-Type"), w.Body.String())
+	}
+}
+
+func TestNilHandlerPanics(t *testing.T) {
+	for name, register := range map[string]func(r *router.Router){
+		"Handler":      func(r *router.Router) { r.GET("/bad", nil) },
+		"HandlerE":     func(r *router.Router) { r.GET("/bad", router.E(nil)) },
+		"TypedHandler": func(r *router.Router) { r.HandleTyped("get", "/bad", nil) },
+	} {
+		func() {
+			defer func() {
+				if msg, _ := recover().(string); !strings.Contains(msg, "GET /bad") {
+					t.Errorf("%s: expected panic naming the route, got %q", name, msg)
+				}
+			}()
+			register(router.New())
+		}()
+	}
+}
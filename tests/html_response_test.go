@@ -3,6 +3,7 @@ package tests
 import (
 	"bytes"
 	"fmt"
+	"html/template"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -64,8 +65,8 @@ func TestHTMLResponse(t *testing.T) {
 	err = templating.InitDefault(templating.Options{
 		Root:         testTemplateRoot,
 		Extensions:   []string{".gb.html", ".html"}, // Corrected: use double quotes for string literals
-		CacheEnabled: false, // Disable cache for testing
-		Debug:        true,  // Enable debug for testing
+		CacheEnabled: false,                         // Disable cache for testing
+		Debug:        true,                          // Enable debug for testing
 		Funcs:        nil,
 	})
 	if err != nil {
@@ -114,3 +115,30 @@ func TestHTMLResponse(t *testing.T) {
 		t.Errorf("Expected body:\n%q\nGot:\n%q", expectedBody, body)
 	}
 }
+
+func TestHTMLResponseURLFunc(t *testing.T) {
+	r := router.New()
+	r.GET("/users/:id", func(ctx *router.Context) {}).Name("users.show")
+
+	root := t.TempDir()
+	err := os.WriteFile(root+"/link.html", []byte(`<a href="{{ url "users.show" "id" .ID }}">profile</a>`), 0644)
+	if err != nil {
+		t.Fatalf("failed to write test template: %v", err)
+	}
+	err = templating.InitDefault(templating.Options{
+		Root:  root,
+		Funcs: template.FuncMap{"url": r.URL},
+	})
+	if err != nil {
+		t.Fatalf("failed to initialize templating engine: %v", err)
+	}
+
+	recorder := httptest.NewRecorder()
+	ctx := router.NewContext(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	response.HTML(ctx, http.StatusOK, "link", response.H{"ID": 42})
+
+	expected := `<a href="/users/42">profile</a>`
+	if body := recorder.Body.String(); body != expected {
+		t.Errorf("Expected body %q, got %q", expected, body)
+	}
+}
@@ -0,0 +1,144 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alejandrombjs/go-bastion-lib/pkg/router"
+)
+
+type jsonPayload struct {
+	Name  string `json:"name"`
+	Owner struct {
+		Age int `json:"age"`
+	} `json:"owner"`
+	Extra any `json:"extra"`
+}
+
+func TestBindJSONErrors(t *testing.T) {
+	r := router.New()
+	opts := router.DefaultOptions()
+	opts.JSON.DisallowUnknownFields = true
+	opts.JSON.RequireContentType = true
+	opts.JSON.RejectTrailingData = true
+	r.SetOptions(opts)
+
+	var lastErr error
+	handler := func(ctx *router.Context) {
+		var p jsonPayload
+		lastErr = ctx.BindJSON(&p)
+	}
+	r.POST("/items", handler)
+	r.POST("/small", handler).JSONOptions(router.JSONOptions{MaxBytes: 16})
+
+	cases := []struct {
+		path, contentType, body string
+		kind                    router.JSONErrorKind
+		status                  int
+		field                   string
+	}{
+		{"/items", "application/json", `{"name":"a"}`, "", 0, ""},
+		{"/items", "application/json", `{"name":`, router.JSONSyntax, 400, ""},
+		{"/items", "application/json", ``, router.JSONSyntax, 400, ""},
+		{"/items", "application/json", `{"owner":{"age":"old"}}`, router.JSONTypeMismatch, 400, "owner.age"},
+		{"/items", "application/json", `{"nme":"a"}`, router.JSONUnknownField, 400, "nme"},
+		{"/items", "application/json", `{"name":"a"} {"name":"b"}`, router.JSONTrailingData, 400, ""},
+		{"/items", "text/plain", `{"name":"a"}`, router.JSONUnsupportedMediaType, 415, ""},
+		{"/small", "text/plain", `{"name":"a"}`, "", 0, ""},
+		{"/small", "", `{"name":"abcdefghijklmnop"}`, router.JSONTooLarge, 413, ""},
+	}
+	for _, tc := range cases {
+		lastErr = nil
+		req := httptest.NewRequest("POST", tc.path, strings.NewReader(tc.body))
+		if tc.contentType != "" {
+			req.Header.Set("Content-Type", tc.contentType)
+		}
+		r.Handler().ServeHTTP(httptest.NewRecorder(), req)
+
+		if tc.kind == "" {
+			if lastErr != nil {
+				t.Errorf("%s %s: unexpected error %v", tc.path, tc.body, lastErr)
+			}
+			continue
+		}
+		var jsonErr *router.JSONError
+		if !errors.As(lastErr, &jsonErr) {
+			t.Errorf("%s %s: expected *router.JSONError, got %v", tc.path, tc.body, lastErr)
+			continue
+		}
+		if jsonErr.Kind != tc.kind || jsonErr.Status != tc.status || jsonErr.Field != tc.field {
+			t.Errorf("%s %s: expected %s/%d/%q, got %s/%d/%q", tc.path, tc.body, tc.kind, tc.status, tc.field,
+				jsonErr.Kind, jsonErr.Status, jsonErr.Field)
+		}
+	}
+}
+
+// Size limits and trailing-data checks are opt-in: a router with the
+// default options decodes the first value of any body.
+func TestBindJSONDefaults(t *testing.T) {
+	r := router.New()
+	var p jsonPayload
+	var lastErr error
+	r.POST("/items", func(ctx *router.Context) {
+		p = jsonPayload{}
+		lastErr = ctx.BindJSON(&p)
+	})
+
+	for _, body := range []string{
+		`{"name":"a"} {"name":"b"}`,
+		`{"name":"a","extra":"` + strings.Repeat("x", 2<<20) + `"}`,
+	} {
+		req := httptest.NewRequest("POST", "/items", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.Handler().ServeHTTP(httptest.NewRecorder(), req)
+
+		if lastErr != nil || p.Name != "a" {
+			t.Errorf("body of %d bytes: expected name a, got %q (%v)", len(body), p.Name, lastErr)
+		}
+	}
+}
+
+func TestBindJSONUseNumber(t *testing.T) {
+	r := router.New()
+	var p jsonPayload
+	r.POST("/items", func(ctx *router.Context) {
+		if err := ctx.BindJSON(&p); err != nil {
+			t.Fatalf("BindJSON: %v", err)
+		}
+	}).JSONOptions(router.JSONOptions{UseNumber: true})
+
+	req := httptest.NewRequest("POST", "/items", strings.NewReader(`{"extra":12345678901234567890}`))
+	r.Handler().ServeHTTP(httptest.NewRecorder(), req)
+
+	if n, ok := p.Extra.(json.Number); !ok || n.String() != "12345678901234567890" {
+		t.Errorf("Expected json.Number, got %T %v", p.Extra, p.Extra)
+	}
+}
+
+func TestBindJSONErrorResponse(t *testing.T) {
+	r := router.New()
+	r.POST("/items", func(ctx *router.Context) {
+		var p jsonPayload
+		if err := ctx.Bind(&p); err != nil {
+			var jsonErr *router.JSONError
+			if errors.As(err, &jsonErr) {
+				ctx.JSON(jsonErr.Status, map[string]string{"code": string(jsonErr.Kind)})
+			}
+			return
+		}
+		ctx.Status(http.StatusNoContent)
+	}).JSONOptions(router.JSONOptions{MaxBytes: 8})
+
+	req := httptest.NewRequest("POST", "/items", strings.NewReader(`{"name":"too long"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.Handler().ServeHTTP(w, req)
+
+	if w.Code != http.StatusRequestEntityTooLarge || !strings.Contains(w.Body.String(), "body_too_large") {
+		t.Errorf("Expected 413 body_too_large, got %d %s", w.Code, w.Body.String())
+	}
+}
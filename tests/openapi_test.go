@@ -0,0 +1,214 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alejandrombjs/go-bastion-lib/pkg/openapi"
+	"github.com/alejandrombjs/go-bastion-lib/pkg/router"
+)
+
+type docCreateUser struct {
+	OrgID  int64  `path:"org"`
+	DryRun bool   `query:"dry_run"`
+	Name   string `json:"name" validate:"required,min=2,max=64"`
+	Email  string `json:"email" validate:"required,email"`
+	Role   string `json:"role" validate:"oneof=admin member"`
+}
+
+type docUser struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+func newDocRouter() *router.Router {
+	r := router.New()
+	r.HandleTyped(http.MethodPost, "/orgs/:org/users", router.Typed(func(ctx *router.Context, in docCreateUser) (docUser, error) {
+		return docUser{}, nil
+	})).Meta("summary", "Create a user").Meta("tags", []string{"Users"})
+	r.GET("/users/:id<int>", func(ctx *router.Context) {}).
+		Name("users.show").
+		Meta("auth", true)
+	r.GET("/internal", func(ctx *router.Context) {}).Meta("hidden", true)
+	openapi.Register(r, openapi.Config{Title: "Test API", Version: "1.0.0"})
+	return r
+}
+
+func fetchDoc(t *testing.T, r *router.Router, path string) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET %s: status = %d, body %s", path, rec.Code, rec.Body)
+	}
+	return rec
+}
+
+func TestOpenAPIDocument(t *testing.T) {
+	rec := fetchDoc(t, newDocRouter(), "/openapi.json")
+
+	var doc struct {
+		OpenAPI string `json:"openapi"`
+		Info    struct {
+			Title string `json:"title"`
+		} `json:"info"`
+		Paths map[string]map[string]struct {
+			Summary     string                `json:"summary"`
+			OperationID string                `json:"operationId"`
+			Tags        []string              `json:"tags"`
+			Parameters  []map[string]any      `json:"parameters"`
+			RequestBody map[string]any        `json:"requestBody"`
+			Responses   map[string]any        `json:"responses"`
+			Security    []map[string][]string `json:"security"`
+		} `json:"paths"`
+		Components struct {
+			Schemas         map[string]map[string]any `json:"schemas"`
+			SecuritySchemes map[string]map[string]any `json:"securitySchemes"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("decode: %v\n%s", err, rec.Body)
+	}
+
+	if doc.OpenAPI != "3.1.0" || doc.Info.Title != "Test API" {
+		t.Fatalf("openapi = %q, title = %q", doc.OpenAPI, doc.Info.Title)
+	}
+	if _, ok := doc.Paths["/internal"]; ok {
+		t.Error("hidden route documented")
+	}
+	if _, ok := doc.Paths["/openapi.json"]; ok {
+		t.Error("document route documented")
+	}
+
+	create := doc.Paths["/orgs/{org}/users"]["post"]
+	if create.Summary != "Create a user" || len(create.Tags) != 1 || create.Tags[0] != "Users" {
+		t.Errorf("create: summary %q, tags %v", create.Summary, create.Tags)
+	}
+	if len(create.Parameters) != 2 || create.Parameters[0]["name"] != "org" || create.Parameters[1]["name"] != "dry_run" {
+		t.Errorf("create parameters = %v", create.Parameters)
+	}
+	for _, code := range []string{"201", "422", "default"} {
+		if _, ok := create.Responses[code]; !ok {
+			t.Errorf("create: missing %s response in %v", code, create.Responses)
+		}
+	}
+	if create.RequestBody == nil {
+		t.Fatal("create: no request body")
+	}
+
+	body := doc.Components.Schemas["docCreateUser"]
+	props, _ := body["properties"].(map[string]any)
+	if _, ok := props["org"]; ok || len(props) != 3 {
+		t.Errorf("request body properties = %v", props)
+	}
+	if req, _ := json.Marshal(body["required"]); string(req) != `["name","email"]` {
+		t.Errorf("required = %s", req)
+	}
+	name, _ := props["name"].(map[string]any)
+	if name["minLength"] != 2.0 || name["maxLength"] != 64.0 {
+		t.Errorf("name schema = %v", name)
+	}
+	if email, _ := props["email"].(map[string]any); email["format"] != "email" {
+		t.Errorf("email schema = %v", email)
+	}
+	if _, ok := doc.Components.Schemas["docUser"]; !ok {
+		t.Error("response schema missing")
+	}
+
+	show := doc.Paths["/users/{id}"]["get"]
+	if show.OperationID != "users.show" {
+		t.Errorf("operationId = %q", show.OperationID)
+	}
+	if len(show.Parameters) != 1 {
+		t.Fatalf("show parameters = %v", show.Parameters)
+	}
+	if schema, _ := show.Parameters[0]["schema"].(map[string]any); schema["type"] != "integer" {
+		t.Errorf("id schema = %v", schema)
+	}
+	if len(show.Security) != 1 || show.Security[0]["bearerAuth"] == nil {
+		t.Errorf("security = %v", show.Security)
+	}
+	if _, ok := show.Responses["401"]; !ok {
+		t.Error("secured route has no 401 response")
+	}
+	if doc.Components.SecuritySchemes["bearerAuth"]["scheme"] != "bearer" {
+		t.Errorf("security schemes = %v", doc.Components.SecuritySchemes)
+	}
+}
+
+func TestOpenAPIYAML(t *testing.T) {
+	rec := fetchDoc(t, newDocRouter(), "/openapi.yaml")
+
+	if ct := rec.Header().Get("Content-Type"); ct != "application/yaml" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	out := rec.Body.String()
+	for _, want := range []string{
+		"openapi: \"3.1.0\"\n",
+		"\n  title: Test API\n",
+		"\n  \"/orgs/{org}/users\":\n",
+		"\"$ref\": \"#/components/schemas/docUser\"",
+		"\n        - name: org\n          in: path\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("YAML missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestOpenAPIDocsUI(t *testing.T) {
+	r := newDocRouter()
+	api := r.Group("/api")
+	openapi.Register(api, openapi.Config{Title: "Test API", Version: "1.0.0"})
+	openapi.Docs(api, openapi.DocsConfig{Path: "/reference", Title: "Test <API>"})
+	openapi.Docs(r, openapi.DocsConfig{SpecURL: "/specs/api.json"})
+
+	page := fetchDoc(t, r, "/api/reference")
+	body := page.Body.String()
+	for _, want := range []string{
+		"<title>Test &lt;API&gt;</title>",
+		`data-spec="/api/openapi.json"`,
+		`<script src="/api/reference/assets/docs.js">`,
+		`href="/api/reference/assets/docs.css"`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("page missing %q:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, "http://") || strings.Contains(body, "https://") {
+		t.Error("page references another origin")
+	}
+
+	js := fetchDoc(t, r, "/api/reference/assets/docs.js")
+	if ct := js.Header().Get("Content-Type"); !strings.Contains(ct, "javascript") {
+		t.Errorf("docs.js Content-Type = %q", ct)
+	}
+	if strings.Contains(js.Body.String(), "https://") {
+		t.Error("docs.js references another origin")
+	}
+	fetchDoc(t, r, "/api/reference/assets/docs.css")
+
+	fetchDoc(t, r, "/api/openapi.json")
+	if body := fetchDoc(t, r, "/docs").Body.String(); !strings.Contains(body, `data-spec="/specs/api.json"`) {
+		t.Errorf("SpecURL not used:\n%s", body)
+	}
+
+	doc := fetchDoc(t, r, "/openapi.json").Body.String()
+	if strings.Contains(doc, "/reference") {
+		t.Error("docs routes are documented")
+	}
+}
+
+func TestOpenAPIDocsDisabled(t *testing.T) {
+	r := router.New()
+	openapi.Docs(r, openapi.DocsConfig{Disabled: true})
+
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/docs", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", rec.Code)
+	}
+}
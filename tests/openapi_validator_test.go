@@ -0,0 +1,351 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alejandrombjs/go-bastion-lib/pkg/openapi"
+	"github.com/alejandrombjs/go-bastion-lib/pkg/router"
+)
+
+const validatorSpec = `
+openapi: 3.0.3
+info:
+  title: Users
+  version: "1.0"
+servers:
+  - url: https://api.example.com/v1
+paths:
+  /users/{id}:
+    parameters:
+      - name: id
+        in: path
+        required: true
+        schema: {type: integer, minimum: 1}
+    get:
+      parameters:
+        - name: fields
+          in: query
+          schema:
+            type: array
+            items: {type: string, enum: [name, email]}
+        - name: X-Tenant
+          in: header
+          required: true
+          schema: {type: string}
+      responses:
+        '200':
+          description: The user
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/User'}
+  /users:
+    post:
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema: {$ref: '#/components/schemas/NewUser'}
+      responses:
+        '201':
+          description: Created
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/User'}
+components:
+  schemas:
+    User:
+      type: object
+      required: [id, name]
+      properties:
+        id: {type: integer, readOnly: true}
+        name: {type: string}
+    NewUser:
+      type: object
+      required: [name, email]
+      additionalProperties: false
+      properties:
+        name: {type: string, minLength: 2}
+        email: {type: string, format: email}
+        tags:
+          type: array
+          uniqueItems: true
+          items: {type: string}
+`
+
+type specErrorBody struct {
+	Error struct {
+		Code    string                    `json:"code"`
+		Details []openapi.ValidationError `json:"details"`
+	} `json:"error"`
+}
+
+func newValidatedRouter(t *testing.T, cfg openapi.ValidatorConfig) *router.Router {
+	t.Helper()
+	spec, err := openapi.ParseSpec([]byte(validatorSpec))
+	if err != nil {
+		t.Fatalf("ParseSpec: %v", err)
+	}
+
+	r := router.New()
+	r.Use(openapi.Validator(spec, cfg))
+	r.GET("/v1/users/:id", func(ctx *router.Context) {
+		if ctx.Param("id") == "99" {
+			ctx.JSON(http.StatusOK, map[string]any{"id": "99"})
+			return
+		}
+		ctx.JSON(http.StatusOK, map[string]any{"id": 1, "name": "Ada"})
+	})
+	r.POST("/v1/users", func(ctx *router.Context) {
+		var in struct {
+			Name string `json:"name"`
+		}
+		if err := ctx.BindJSON(&in); err != nil {
+			t.Errorf("body not readable after validation: %v", err)
+		}
+		ctx.JSON(http.StatusCreated, map[string]any{"id": 1, "name": in.Name})
+	})
+	return r
+}
+
+func serveSpecRequest(r *router.Router, method, target, contentType, body string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, req)
+	return rec
+}
+
+func pointers(t *testing.T, rec *httptest.ResponseRecorder) []string {
+	t.Helper()
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400; body %s", rec.Code, rec.Body)
+	}
+	var body specErrorBody
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if body.Error.Code != "invalid_request" {
+		t.Errorf("code = %q", body.Error.Code)
+	}
+	var got []string
+	for _, d := range body.Error.Details {
+		got = append(got, d.In+" "+d.Pointer)
+	}
+	return got
+}
+
+func TestOpenAPIValidatorRequests(t *testing.T) {
+	var logs bytes.Buffer
+	r := newValidatedRouter(t, openapi.ValidatorConfig{Logger: log.New(&logs, "", 0)})
+	tenant := http.Header{"X-Tenant": {"acme"}}
+
+	tests := []struct {
+		name        string
+		method      string
+		target      string
+		contentType string
+		body        string
+		header      http.Header
+		want        []string
+	}{
+		{"path type and missing header", http.MethodGet, "/v1/users/abc", "", "", nil,
+			[]string{"path /id", "header /X-Tenant"}},
+		{"minimum and array item enum", http.MethodGet, "/v1/users/0?fields=name&fields=bogus", "", "", tenant,
+			[]string{"path /id", "query /fields/1"}},
+		{"body", http.MethodPost, "/v1/users", "application/json",
+			`{"name":"A","email":"nope","tags":["x","x"],"extra":1}`, nil,
+			[]string{"body /email", "body /extra", "body /name", "body /tags/1"}},
+		{"missing body", http.MethodPost, "/v1/users", "application/json", "", nil,
+			[]string{"body "}},
+		{"invalid JSON", http.MethodPost, "/v1/users", "application/json", `{"name":`, nil,
+			[]string{"body "}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serveSpecRequest(r, tt.method, tt.target, tt.contentType, tt.body, tt.header)
+			if got := pointers(t, rec); strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("errors = %q, want %q; body %s", got, tt.want, rec.Body)
+			}
+		})
+	}
+
+	if rec := serveSpecRequest(r, http.MethodGet, "/v1/users/1?fields=name&fields=email", "", "", tenant); rec.Code != http.StatusOK {
+		t.Errorf("valid GET: status = %d, body %s", rec.Code, rec.Body)
+	}
+	if rec := serveSpecRequest(r, http.MethodPost, "/v1/users", "application/json", `{"name":"Ada","email":"ada@example.com"}`, nil); rec.Code != http.StatusCreated || !strings.Contains(rec.Body.String(), `"Ada"`) {
+		t.Errorf("valid POST: status = %d, body %s", rec.Code, rec.Body)
+	}
+	if rec := serveSpecRequest(r, http.MethodPost, "/v1/users", "text/plain", "Ada", nil); rec.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("text/plain POST: status = %d, want 415", rec.Code)
+	}
+	if rec := serveSpecRequest(r, http.MethodGet, "/v1/orders", "", "", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("undocumented path: status = %d, want 404", rec.Code)
+	}
+	if rec := serveSpecRequest(r, http.MethodGet, "/v1/users/99", "", "", tenant); rec.Code != http.StatusOK {
+		t.Errorf("invalid response: status = %d, want it passed through", rec.Code)
+	}
+	if strings.Contains(logs.String(), "response violates") {
+		t.Errorf("responses validated without ValidateResponses:\n%s", logs.String())
+	}
+}
+
+// Only bodies validated against a JSON schema are buffered and limited;
+// uploads stream through to the handler whatever their size.
+func TestOpenAPIValidatorBodyLimit(t *testing.T) {
+	spec, err := openapi.ParseSpec([]byte(`
+openapi: 3.0.3
+info: {title: Files, version: "1"}
+paths:
+  /files:
+    post:
+      requestBody:
+        required: true
+        content:
+          multipart/form-data:
+            schema:
+              type: object
+              properties:
+                file: {type: string, format: binary}
+          application/json:
+            schema: {type: object}
+      responses:
+        '201': {description: Stored}
+`))
+	if err != nil {
+		t.Fatalf("ParseSpec: %v", err)
+	}
+
+	r := router.New()
+	r.Use(openapi.Validator(spec, openapi.ValidatorConfig{MaxBodyBytes: 1024}))
+	r.POST("/files", func(ctx *router.Context) {
+		n, err := io.Copy(io.Discard, ctx.Request().Body)
+		if err != nil {
+			t.Errorf("read body: %v", err)
+		}
+		ctx.JSON(http.StatusCreated, map[string]int64{"size": n})
+	})
+
+	upload := strings.Repeat("x", 4096)
+	rec := serveSpecRequest(r, http.MethodPost, "/files", "multipart/form-data; boundary=b", upload, nil)
+	if rec.Code != http.StatusCreated || !strings.Contains(rec.Body.String(), `"size":4096`) {
+		t.Errorf("large upload: status = %d, body %s", rec.Code, rec.Body)
+	}
+
+	rec = serveSpecRequest(r, http.MethodPost, "/files", "application/json", `{"pad":"`+upload+`"}`, nil)
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("large JSON body: status = %d, want 413", rec.Code)
+	}
+	rec = serveSpecRequest(r, http.MethodPost, "/files", "multipart/form-data; boundary=b", "", nil)
+	if got := pointers(t, rec); strings.Join(got, ",") != "body " {
+		t.Errorf("empty upload: errors = %q, want the body reported missing", got)
+	}
+}
+
+func TestOpenAPIValidatorResponses(t *testing.T) {
+	var logs bytes.Buffer
+	r := newValidatedRouter(t, openapi.ValidatorConfig{ValidateResponses: true, Logger: log.New(&logs, "", 0)})
+	tenant := http.Header{"X-Tenant": {"acme"}}
+
+	rec := serveSpecRequest(r, http.MethodGet, "/v1/users/1", "", "", tenant)
+	if rec.Code != http.StatusOK || logs.Len() != 0 {
+		t.Fatalf("valid response: status = %d, logs %q", rec.Code, logs.String())
+	}
+
+	rec = serveSpecRequest(r, http.MethodGet, "/v1/users/99", "", "", tenant)
+	if rec.Code != http.StatusOK || rec.Body.String() != `{"id":"99"}`+"\n" {
+		t.Errorf("response altered: %d %q", rec.Code, rec.Body)
+	}
+	out := logs.String()
+	for _, want := range []string{
+		"GET /v1/users/99 200 response violates the spec: body /id: must be integer",
+		"body /name: is required",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("logs missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestOpenAPILoadSpec(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "openapi.yaml")
+	if err := os.WriteFile(path, []byte(validatorSpec), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := openapi.LoadSpec(path); err != nil {
+		t.Fatalf("LoadSpec: %v", err)
+	}
+
+	if _, err := openapi.ParseSpec([]byte("swagger: \"2.0\"\npaths: {}\n")); err == nil {
+		t.Error("Swagger 2.0 document accepted")
+	}
+	if _, err := openapi.LoadSpec(filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Error("missing file accepted")
+	}
+
+	// The generated document can be loaded back for validation.
+	doc, err := openapi.Generate(newDocRouter(), openapi.Config{Title: "Test API", Version: "1.0.0"}).YAML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := openapi.ParseSpec(doc); err != nil {
+		t.Errorf("ParseSpec(generated YAML): %v", err)
+	}
+}
+
+// The reference document shipped in docs/ must stay loadable.
+func TestOpenAPILoadRepositorySpec(t *testing.T) {
+	spec, err := openapi.LoadSpec(filepath.Join("..", "docs", "openapi.yaml"))
+	if err != nil {
+		t.Fatalf("LoadSpec(docs/openapi.yaml): %v", err)
+	}
+	if spec == nil {
+		t.Fatal("LoadSpec returned a nil spec")
+	}
+}
+
+func TestOpenAPIValidatorRecursiveSchema(t *testing.T) {
+	spec, err := openapi.ParseSpec([]byte(`
+openapi: 3.1.0
+info: {title: Loop, version: "1"}
+paths:
+  /loop:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema: {$ref: '#/components/schemas/A'}
+      responses:
+        '204': {description: Done}
+components:
+  schemas:
+    A:
+      anyOf:
+        - $ref: '#/components/schemas/A'
+`))
+	if err != nil {
+		t.Fatalf("ParseSpec: %v", err)
+	}
+	r := router.New()
+	r.Use(openapi.Validator(spec, openapi.ValidatorConfig{}))
+	r.POST("/loop", func(ctx *router.Context) { ctx.Status(http.StatusNoContent) })
+
+	rec := serveSpecRequest(r, http.MethodPost, "/loop", "application/json", `{}`, nil)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400 for a schema that never terminates", rec.Code)
+	}
+}
@@ -0,0 +1,69 @@
+package tests
+
+import (
+	"html/template"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alejandrombjs/go-bastion-lib/pkg/router"
+)
+
+type renderItem struct {
+	ID   int    `json:"id" xml:"id" csv:"id"`
+	Name string `json:"name" xml:"name" csv:"name"`
+}
+
+func (i renderItem) String() string {
+	return i.Name
+}
+
+func TestNegotiate(t *testing.T) {
+	r := router.New()
+	r.RegisterRenderer(router.HTMLRenderer{Template: template.Must(template.New("item").Parse(`<h1>{{.Name}}</h1>`))})
+	r.GET("/item", func(ctx *router.Context) {
+		ctx.Negotiate(200, renderItem{ID: 1, Name: "Widget"})
+	})
+	r.GET("/items", func(ctx *router.Context) {
+		ctx.Negotiate(200, []renderItem{{ID: 1, Name: "Widget"}, {ID: 2, Name: "Gadget"}})
+	})
+	r.GET("/map", func(ctx *router.Context) {
+		ctx.Negotiate(200, map[string]int{"count": 2})
+	})
+
+	cases := []struct {
+		path, accept string
+		status       int
+		contentType  string
+		body         string
+	}{
+		{"/item", "", 200, "application/json", `{"id":1,"name":"Widget"}`},
+		{"/item", "text/html,application/xhtml+xml,*/*;q=0.8", 200, "text/html; charset=utf-8", `<h1>Widget</h1>`},
+		{"/item", "application/json;q=0.5, application/xml", 200, "application/xml", `<renderItem><id>1</id><name>Widget</name></renderItem>`},
+		{"/item", "text/*;q=0.9, text/plain;q=0.1", 200, "text/html; charset=utf-8", `<h1>Widget</h1>`},
+		{"/item", "text/plain", 200, "text/plain; charset=utf-8", "Widget"},
+		{"/items", "text/csv", 200, "text/csv; charset=utf-8", "id,name\n1,Widget\n2,Gadget"},
+		{"/map", "application/xml, application/json;q=0.1", 200, "application/json", `{"count":2}`},
+		{"/item", "application/problem+json", 200, "application/json", `{"id":1,"name":"Widget"}`},
+		{"/item", "image/png", 406, "application/json", `{"error":"Not Acceptable"}`},
+		{"/item", "application/json;q=0", 406, "application/json", `{"error":"Not Acceptable"}`},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest("GET", tc.path, nil)
+		if tc.accept != "" {
+			req.Header.Set("Accept", tc.accept)
+		}
+		w := httptest.NewRecorder()
+		r.Handler().ServeHTTP(w, req)
+
+		if w.Code != tc.status || w.Header().Get("Content-Type") != tc.contentType {
+			t.Errorf("%s Accept %q: expected %d %s, got %d %s", tc.path, tc.accept, tc.status, tc.contentType, w.Code, w.Header().Get("Content-Type"))
+		}
+		if !strings.Contains(w.Body.String(), tc.body) {
+			t.Errorf("%s Accept %q: expected body containing %q, got %q", tc.path, tc.accept, tc.body, w.Body.String())
+		}
+		if w.Header().Get("Vary") != "Accept" {
+			t.Errorf("%s: expected Vary: Accept", tc.path)
+		}
+	}
+}
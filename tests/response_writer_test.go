@@ -0,0 +1,116 @@
+package tests
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alejandrombjs/go-bastion-lib/pkg/middleware"
+	"github.com/alejandrombjs/go-bastion-lib/pkg/router"
+)
+
+func TestResponseTracksDirectWrites(t *testing.T) {
+	var logs bytes.Buffer
+	r := router.New()
+	r.Use(middleware.Logging(log.New(&logs, "", 0)))
+
+	var status int
+	var size int64
+	var firstByte bool
+	r.Use(func(next router.Handler) router.Handler {
+		return func(ctx *router.Context) {
+			next(ctx)
+			status, size = ctx.StatusCode(), ctx.Response().Size()
+			firstByte = !ctx.Response().FirstByte().IsZero()
+		}
+	})
+	r.GET("/direct", func(ctx *router.Context) {
+		w := ctx.ResponseWriter()
+		w.WriteHeader(http.StatusAccepted)
+		io.WriteString(w, "queued")
+	})
+	r.GET("/copy", func(ctx *router.Context) {
+		io.Copy(ctx.ResponseWriter(), strings.NewReader("streamed body"))
+	})
+	r.GET("/wrapped", router.WrapH(http.NotFoundHandler()))
+
+	cases := []struct {
+		path   string
+		status int
+		size   int64
+	}{
+		{"/direct", http.StatusAccepted, 6},
+		{"/copy", http.StatusOK, 13},
+		{"/wrapped", http.StatusNotFound, 19},
+	}
+	for _, tc := range cases {
+		logs.Reset()
+		w := httptest.NewRecorder()
+		r.Handler().ServeHTTP(w, httptest.NewRequest("GET", tc.path, nil))
+
+		if status != tc.status || size != tc.size || !firstByte {
+			t.Errorf("%s: expected %d/%d bytes, got %d/%d (first byte recorded: %v)", tc.path, tc.status, tc.size, status, size, firstByte)
+		}
+		if w.Code != tc.status {
+			t.Errorf("%s: recorder got %d", tc.path, w.Code)
+		}
+		if want := fmt.Sprintf("GET %s %d ", tc.path, tc.status); !strings.Contains(logs.String(), want) {
+			t.Errorf("%s: expected status %d in log, got %q", tc.path, tc.status, logs.String())
+		}
+	}
+}
+
+func TestResponseFlushAndHijack(t *testing.T) {
+	hijackStatus := make(chan int, 1)
+	r := router.New()
+	r.Use(func(next router.Handler) router.Handler {
+		return func(ctx *router.Context) {
+			next(ctx)
+			if ctx.Request().URL.Path == "/upgrade" {
+				hijackStatus <- ctx.StatusCode()
+			}
+		}
+	})
+	r.GET("/stream", func(ctx *router.Context) {
+		io.WriteString(ctx.ResponseWriter(), "data: 1\n\n")
+		if err := http.NewResponseController(ctx.ResponseWriter()).Flush(); err != nil {
+			t.Errorf("Flush: %v", err)
+		}
+	})
+	r.GET("/upgrade", func(ctx *router.Context) {
+		conn, buf, err := http.NewResponseController(ctx.ResponseWriter()).Hijack()
+		if err != nil {
+			t.Errorf("Hijack: %v", err)
+			return
+		}
+		defer conn.Close()
+		buf.WriteString("HTTP/1.1 200 OK\r\nContent-Length: 8\r\nConnection: close\r\n\r\nhijacked")
+		buf.Flush()
+	})
+
+	w := httptest.NewRecorder()
+	r.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/stream", nil))
+	if !w.Flushed {
+		t.Error("Expected the response to be flushed")
+	}
+
+	srv := httptest.NewServer(r.Handler())
+	defer srv.Close()
+	resp, err := http.Get(srv.URL + "/upgrade")
+	if err != nil {
+		t.Fatalf("GET /upgrade: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "hijacked" {
+		t.Errorf("Expected hijacked body, got %q", body)
+	}
+	if status := <-hijackStatus; status != http.StatusSwitchingProtocols {
+		t.Errorf("Expected 101 for a hijacked connection, got %d", status)
+	}
+}
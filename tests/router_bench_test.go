@@ -0,0 +1,83 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alejandrombjs/go-bastion-lib/pkg/router"
+)
+
+// discardWriter is a ResponseWriter that allocates nothing per request.
+type discardWriter struct {
+	header http.Header
+}
+
+func (w *discardWriter) Header() http.Header         { return w.header }
+func (w *discardWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (w *discardWriter) WriteHeader(int)             {}
+
+func newBenchRouter() http.Handler {
+	r := router.New()
+	h := func(ctx *router.Context) {
+		ctx.Status(http.StatusOK)
+	}
+	for _, p := range []string{"/", "/about", "/contact", "/api/health", "/api/users", "/api/posts", "/api/comments"} {
+		r.GET(p, h)
+	}
+	r.GET("/api/users/:id<int>", func(ctx *router.Context) {
+		_ = ctx.Param("id")
+		ctx.Status(http.StatusOK)
+	})
+	r.GET("/api/users/:id/posts/:post", func(ctx *router.Context) {
+		_ = ctx.Param("post")
+		ctx.Status(http.StatusOK)
+	})
+	r.GET("/static/*filepath", h)
+	return r.Handler()
+}
+
+func TestRouterZeroAllocs(t *testing.T) {
+	handler := newBenchRouter()
+	w := &discardWriter{header: make(http.Header)}
+
+	for _, path := range []string{"/api/users", "/api/users/42", "/api/users/jane/posts/7", "/static/css/app.css"} {
+		req := httptest.NewRequest("GET", path, nil)
+		handler.ServeHTTP(w, req) // warm up the Context pool
+
+		allocs := testing.AllocsPerRun(100, func() {
+			handler.ServeHTTP(w, req)
+		})
+		if allocs != 0 {
+			t.Errorf("%s: expected 0 allocs per request, got %v", path, allocs)
+		}
+	}
+}
+
+func benchmarkRoute(b *testing.B, path string) {
+	handler := newBenchRouter()
+	w := &discardWriter{header: make(http.Header)}
+	req := httptest.NewRequest("GET", path, nil)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		handler.ServeHTTP(w, req)
+	}
+}
+
+func BenchmarkRouterStatic(b *testing.B) {
+	benchmarkRoute(b, "/api/comments")
+}
+
+func BenchmarkRouterParam(b *testing.B) {
+	benchmarkRoute(b, "/api/users/42")
+}
+
+func BenchmarkRouterTwoParams(b *testing.B) {
+	benchmarkRoute(b, "/api/users/jane/posts/7")
+}
+
+func BenchmarkRouterCatchAll(b *testing.B) {
+	benchmarkRoute(b, "/static/css/app.css")
+}
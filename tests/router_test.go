@@ -2,7 +2,9 @@
 package tests
 
 import (
+	"fmt"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/alejandrombjs/go-bastion-lib/pkg/router"
@@ -60,3 +62,561 @@ func TestRouterGroup(t *testing.T) {
 		t.Errorf("Expected status 200, got %d", w.Code)
 	}
 }
+
+func TestRouterCatchAll(t *testing.T) {
+	r := router.New()
+
+	r.GET("/static/*filepath", func(ctx *router.Context) {
+		ctx.JSON(200, map[string]string{"filepath": ctx.Param("filepath")})
+	})
+	r.GET("/static/:name/info", func(ctx *router.Context) {
+		ctx.JSON(200, map[string]string{"name": ctx.Param("name")})
+	})
+	r.GET("/static/robots.txt", func(ctx *router.Context) {
+		ctx.JSON(200, map[string]string{"static": "robots"})
+	})
+
+	cases := map[string]string{
+		"/static/css/site/main.css": `{"filepath":"css/site/main.css"}`,
+		"/static/app.js":            `{"filepath":"app.js"}`,
+		"/static":                   `{"filepath":""}`,
+		"/static/logo/info":         `{"name":"logo"}`,
+		"/static/robots.txt":        `{"static":"robots"}`,
+	}
+	for path, want := range cases {
+		req := httptest.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		r.Handler().ServeHTTP(w, req)
+
+		if w.Code != 200 {
+			t.Errorf("%s: expected status 200, got %d", path, w.Code)
+		}
+		if got := strings.TrimSpace(w.Body.String()); got != want {
+			t.Errorf("%s: expected body %s, got %s", path, want, got)
+		}
+	}
+}
+
+func TestRouterCatchAllConflicts(t *testing.T) {
+	h := func(ctx *router.Context) {}
+
+	mustPanic := func(name string, fn func()) {
+		t.Helper()
+		defer func() {
+			if recover() == nil {
+				t.Errorf("%s: expected registration to panic", name)
+			}
+		}()
+		fn()
+	}
+
+	mustPanic("not last", func() {
+		router.New().GET("/files/*path/edit", h)
+	})
+	mustPanic("unnamed", func() {
+		router.New().GET("/files/*", h)
+	})
+	mustPanic("different names", func() {
+		r := router.New()
+		r.GET("/files/*path", h)
+		r.POST("/files/*rest", h)
+	})
+}
+
+func TestRouterParamConstraints(t *testing.T) {
+	r := router.New()
+
+	r.GET("/users/:id<int>", func(ctx *router.Context) {
+		id, err := ctx.ParamInt("id")
+		if err != nil {
+			t.Errorf("ParamInt: %v", err)
+		}
+		ctx.JSON(200, map[string]int{"id": id})
+	})
+	r.GET("/users/:uuid<uuid>", func(ctx *router.Context) {
+		ctx.JSON(200, map[string]string{"uuid": ctx.Param("uuid")})
+	})
+	r.GET("/users/:slug<[a-z0-9-]+>", func(ctx *router.Context) {
+		ctx.JSON(200, map[string]string{"slug": ctx.Param("slug")})
+	})
+
+	cases := []struct {
+		path   string
+		status int
+		body   string
+	}{
+		{"/users/42", 200, `{"id":42}`},
+		{"/users/6ba7b810-9dad-11d1-80b4-00c04fd430c8", 200, `{"uuid":"6ba7b810-9dad-11d1-80b4-00c04fd430c8"}`},
+		{"/users/jane-doe", 200, `{"slug":"jane-doe"}`},
+		{"/users/Jane_Doe", 404, `{"error":"Not Found"}`},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest("GET", tc.path, nil)
+		w := httptest.NewRecorder()
+		r.Handler().ServeHTTP(w, req)
+
+		if w.Code != tc.status {
+			t.Errorf("%s: expected status %d, got %d", tc.path, tc.status, w.Code)
+		}
+		if got := strings.TrimSpace(w.Body.String()); got != tc.body {
+			t.Errorf("%s: expected body %s, got %s", tc.path, tc.body, got)
+		}
+	}
+}
+
+func TestRouterInvalidConstraint(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected invalid regex constraint to panic")
+		}
+	}()
+	router.New().GET("/users/:id<[0-9>", func(ctx *router.Context) {})
+}
+
+func TestRouterImplicitHeadAndOptions(t *testing.T) {
+	r := router.New()
+
+	r.GET("/items", func(ctx *router.Context) {
+		ctx.JSON(200, map[string]string{"message": "items"})
+	})
+	r.POST("/items", func(ctx *router.Context) {
+		ctx.Status(201)
+	})
+	r.Handle("purge", "/items", func(ctx *router.Context) {
+		ctx.Status(204)
+	})
+
+	req := httptest.NewRequest("HEAD", "/items", nil)
+	w := httptest.NewRecorder()
+	r.Handler().ServeHTTP(w, req)
+	if w.Code != 200 {
+		t.Errorf("HEAD: expected status 200, got %d", w.Code)
+	}
+	if w.Body.Len() != 0 {
+		t.Errorf("HEAD: expected empty body, got %q", w.Body.String())
+	}
+
+	req = httptest.NewRequest("OPTIONS", "/items", nil)
+	w = httptest.NewRecorder()
+	r.Handler().ServeHTTP(w, req)
+	if w.Code != 204 {
+		t.Errorf("OPTIONS: expected status 204, got %d", w.Code)
+	}
+	wantAllow := "GET, HEAD, OPTIONS, POST, PURGE"
+	if got := w.Header().Get("Allow"); got != wantAllow {
+		t.Errorf("OPTIONS: expected Allow %q, got %q", wantAllow, got)
+	}
+
+	req = httptest.NewRequest("DELETE", "/items", nil)
+	w = httptest.NewRecorder()
+	r.Handler().ServeHTTP(w, req)
+	if w.Code != 405 {
+		t.Errorf("DELETE: expected status 405, got %d", w.Code)
+	}
+	if got := w.Header().Get("Allow"); got != wantAllow {
+		t.Errorf("DELETE: expected Allow %q, got %q", wantAllow, got)
+	}
+}
+
+func TestRouterAny(t *testing.T) {
+	r := router.New()
+
+	r.Any("/echo", func(ctx *router.Context) {
+		ctx.JSON(200, map[string]string{"method": ctx.Request().Method})
+	})
+
+	for _, method := range []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"} {
+		req := httptest.NewRequest(method, "/echo", nil)
+		w := httptest.NewRecorder()
+		r.Handler().ServeHTTP(w, req)
+
+		want := `{"method":"` + method + `"}`
+		if got := strings.TrimSpace(w.Body.String()); w.Code != 200 || got != want {
+			t.Errorf("%s: expected 200 %s, got %d %s", method, want, w.Code, got)
+		}
+	}
+}
+
+func TestRouterMiddlewareOrderIndependent(t *testing.T) {
+	r := router.New()
+
+	var trace []string
+	mark := func(name string) router.Middleware {
+		return func(next router.Handler) router.Handler {
+			return func(ctx *router.Context) {
+				trace = append(trace, name)
+				next(ctx)
+			}
+		}
+	}
+
+	api := r.Group("/api")
+	v1 := api.Group("/v1")
+	v1.GET("/users", func(ctx *router.Context) {
+		trace = append(trace, "handler")
+		ctx.Status(200)
+	})
+
+	// Registered after the route on purpose.
+	v1.Use(mark("v1"))
+	r.Use(mark("global"))
+	api.Use(mark("api"))
+
+	req := httptest.NewRequest("GET", "/api/v1/users", nil)
+	w := httptest.NewRecorder()
+	r.Handler().ServeHTTP(w, req)
+
+	want := "global,api,v1,handler"
+	if got := strings.Join(trace, ","); got != want {
+		t.Errorf("Expected middleware order %s, got %s", want, got)
+	}
+
+	trace = nil
+	req = httptest.NewRequest("GET", "/missing", nil)
+	w = httptest.NewRecorder()
+	r.Handler().ServeHTTP(w, req)
+
+	if got := strings.Join(trace, ","); got != "global" || w.Code != 404 {
+		t.Errorf("Expected global middleware around 404, got %q with status %d", got, w.Code)
+	}
+}
+
+func TestRouterPerRouteMiddlewareAndMeta(t *testing.T) {
+	r := router.New()
+
+	var trace []string
+	mark := func(name string) router.Middleware {
+		return func(next router.Handler) router.Handler {
+			return func(ctx *router.Context) {
+				trace = append(trace, name)
+				next(ctx)
+			}
+		}
+	}
+
+	r.Use(mark("global"))
+	r.GET("/public", func(ctx *router.Context) {
+		ctx.Status(200)
+	})
+	r.GET("/admin", func(ctx *router.Context) {
+		scope, _ := ctx.RouteMeta("scope")
+		ctx.JSON(200, map[string]any{
+			"name":    ctx.RouteName(),
+			"pattern": ctx.RoutePattern(),
+			"scope":   scope,
+		})
+	}, mark("auth")).Use(mark("audit")).Name("admin.home").Meta("scope", "admin")
+
+	req := httptest.NewRequest("GET", "/admin", nil)
+	w := httptest.NewRecorder()
+	r.Handler().ServeHTTP(w, req)
+
+	if got := strings.Join(trace, ","); got != "global,auth,audit" {
+		t.Errorf("Expected middleware order global,auth,audit, got %s", got)
+	}
+	want := `{"name":"admin.home","pattern":"/admin","scope":"admin"}`
+	if got := strings.TrimSpace(w.Body.String()); got != want {
+		t.Errorf("Expected body %s, got %s", want, got)
+	}
+
+	trace = nil
+	req = httptest.NewRequest("GET", "/public", nil)
+	w = httptest.NewRecorder()
+	r.Handler().ServeHTTP(w, req)
+
+	if got := strings.Join(trace, ","); got != "global" {
+		t.Errorf("Expected only global middleware on /public, got %s", got)
+	}
+}
+
+func TestRouterURL(t *testing.T) {
+	r := router.New()
+	h := func(ctx *router.Context) {}
+
+	r.GET("/users/:id<int>", h).Name("users.show")
+	r.Group("/files").GET("/*filepath", h).Name("files")
+
+	cases := []struct {
+		name   string
+		params []any
+		want   string
+	}{
+		{"users.show", []any{"id", 42}, "/users/42"},
+		{"users.show", []any{"id", 7, "tab", "posts"}, "/users/7?tab=posts"},
+		{"files", []any{"filepath", "docs/a b.txt"}, "/files/docs/a%20b.txt"},
+	}
+	for _, tc := range cases {
+		got, err := r.URL(tc.name, tc.params...)
+		if err != nil {
+			t.Errorf("URL(%s, %v): unexpected error: %v", tc.name, tc.params, err)
+		}
+		if got != tc.want {
+			t.Errorf("URL(%s, %v): expected %s, got %s", tc.name, tc.params, tc.want, got)
+		}
+	}
+
+	if _, err := r.URL("users.show"); err == nil {
+		t.Error("Expected error for missing parameter")
+	}
+	if _, err := r.URL("users.show", "id", "abc"); err == nil {
+		t.Error("Expected error for constraint violation")
+	}
+	if _, err := r.URL("nope"); err == nil {
+		t.Error("Expected error for unknown route name")
+	}
+}
+
+func TestRouterRoutes(t *testing.T) {
+	r := router.New()
+	noop := func(next router.Handler) router.Handler { return next }
+	h := func(ctx *router.Context) {}
+
+	r.Use(noop)
+	api := r.Group("/api")
+	api.Use(noop)
+	api.POST("/users", h)
+	api.GET("/users", h).Name("users.index")
+	api.GET("/users/:id", h, noop).Meta("summary", "Show user")
+	api.GET("/internal", h).Meta("hidden", true)
+
+	routes := r.Routes()
+	if len(routes) != 3 {
+		t.Fatalf("Expected 3 routes, got %d", len(routes))
+	}
+
+	want := []router.RouteInfo{
+		{Method: "GET", Path: "/api/users", Name: "users.index", Middleware: 2},
+		{Method: "POST", Path: "/api/users", Middleware: 2},
+		{Method: "GET", Path: "/api/users/:id", Middleware: 3, Meta: map[string]any{"summary": "Show user"}},
+	}
+	for i, w := range want {
+		got := routes[i]
+		if got.Method != w.Method || got.Path != w.Path || got.Name != w.Name || got.Middleware != w.Middleware {
+			t.Errorf("Route %d: expected %+v, got %+v", i, w, got)
+		}
+		if w.Meta != nil && got.Meta["summary"] != w.Meta["summary"] {
+			t.Errorf("Route %d: expected meta %v, got %v", i, w.Meta, got.Meta)
+		}
+	}
+}
+
+func TestRouterHost(t *testing.T) {
+	r := router.New()
+
+	r.GET("/", func(ctx *router.Context) {
+		ctx.JSON(200, map[string]string{"site": "default"})
+	})
+	r.GET("/about", func(ctx *router.Context) {
+		ctx.JSON(200, map[string]string{"site": "about"})
+	})
+	r.Host("admin.example.com").GET("/", func(ctx *router.Context) {
+		ctx.JSON(200, map[string]string{"site": "admin"})
+	})
+	r.Host("{tenant}.example.com").Group("/api").GET("/users/:id", func(ctx *router.Context) {
+		ctx.JSON(200, map[string]string{"tenant": ctx.Param("tenant"), "id": ctx.Param("id")})
+	})
+
+	cases := []struct {
+		host, path, body string
+	}{
+		{"admin.example.com", "/", `{"site":"admin"}`},
+		{"ADMIN.example.com:8080", "/", `{"site":"admin"}`},
+		{"acme.example.com", "/api/users/7", `{"id":"7","tenant":"acme"}`},
+		{"acme.example.com", "/about", `{"site":"about"}`},
+		{"other.org", "/", `{"site":"default"}`},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest("GET", tc.path, nil)
+		req.Host = tc.host
+		w := httptest.NewRecorder()
+		r.Handler().ServeHTTP(w, req)
+
+		if got := strings.TrimSpace(w.Body.String()); got != tc.body {
+			t.Errorf("%s%s: expected %s, got %d %s", tc.host, tc.path, tc.body, w.Code, got)
+		}
+	}
+
+	req := httptest.NewRequest("GET", "/api/users/7", nil)
+	req.Host = "example.com"
+	w := httptest.NewRecorder()
+	r.Handler().ServeHTTP(w, req)
+	if w.Code != 404 {
+		t.Errorf("Expected host route not to match bare domain, got %d", w.Code)
+	}
+}
+
+func TestRouterPathFixups(t *testing.T) {
+	newRouter := func(opts router.Options) *router.Router {
+		r := router.New()
+		r.SetOptions(opts)
+		r.GET("/users", func(ctx *router.Context) {
+			ctx.JSON(200, map[string]string{"route": "users"})
+		})
+		r.GET("/docs/", func(ctx *router.Context) {
+			ctx.JSON(200, map[string]string{"route": "docs"})
+		})
+		r.POST("/Admin/:name", func(ctx *router.Context) {
+			ctx.JSON(200, map[string]string{"name": ctx.Param("name")})
+		})
+		return r
+	}
+
+	cases := []struct {
+		opts     router.Options
+		method   string
+		path     string
+		status   int
+		location string
+	}{
+		{router.DefaultOptions(), "GET", "/users/", 200, ""},
+		{router.DefaultOptions(), "GET", "/docs", 200, ""},
+		{router.DefaultOptions(), "GET", "//users/../users", 301, "/users"},
+		{router.DefaultOptions(), "GET", "/USERS", 404, ""},
+		{router.Options{TrailingSlash: router.TrailingSlashRedirect}, "GET", "/users/?page=2", 301, "/users?page=2"},
+		{router.Options{TrailingSlash: router.TrailingSlashRedirect}, "GET", "/docs", 301, "/docs/"},
+		{router.Options{TrailingSlash: router.TrailingSlashStrict}, "GET", "/users/", 404, ""},
+		{router.Options{CaseInsensitive: true}, "GET", "/USERS", 301, "/users"},
+		{router.Options{CaseInsensitive: true}, "POST", "/admin/Bob", 308, "/Admin/Bob"},
+		{router.DefaultOptions(), "OPTIONS", "*", 404, ""},
+		{router.Options{RedirectCleanPath: true, CaseInsensitive: true}, "OPTIONS", "*", 404, ""},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(tc.method, tc.path, nil)
+		w := httptest.NewRecorder()
+		newRouter(tc.opts).Handler().ServeHTTP(w, req)
+
+		if w.Code != tc.status {
+			t.Errorf("%s %s %+v: expected status %d, got %d", tc.method, tc.path, tc.opts, tc.status, w.Code)
+		}
+		if got := w.Header().Get("Location"); got != tc.location {
+			t.Errorf("%s %s %+v: expected Location %q, got %q", tc.method, tc.path, tc.opts, tc.location, got)
+		}
+	}
+}
+
+func TestRouterEncodedParams(t *testing.T) {
+	r := router.New()
+	r.GET("/files/:name/meta", func(ctx *router.Context) {
+		ctx.JSON(200, map[string]string{"name": ctx.Param("name")})
+	})
+
+	req := httptest.NewRequest("GET", "/files/reports%2F2024%20Q1.pdf/meta", nil)
+	w := httptest.NewRecorder()
+	r.Handler().ServeHTTP(w, req)
+
+	want := `{"name":"reports/2024 Q1.pdf"}`
+	if got := strings.TrimSpace(w.Body.String()); w.Code != 200 || got != want {
+		t.Errorf("Expected 200 %s, got %d %s", want, w.Code, got)
+	}
+}
+
+func TestRouterRadixBacktracking(t *testing.T) {
+	r := router.New()
+	reply := func(name string) router.Handler {
+		return func(ctx *router.Context) {
+			ctx.JSON(200, map[string]string{"route": name, "v": ctx.Param("v")})
+		}
+	}
+
+	r.GET("/static/*v", reply("catchall"))
+	r.GET("/statistics", reply("stats"))
+	r.GET("/search", reply("search"))
+	r.GET("/users/new", reply("new"))
+	r.GET("/users/:v", reply("param"))
+	r.GET("/users/:v/edit", reply("edit"))
+
+	cases := map[string]string{
+		"/static":         `{"route":"catchall","v":""}`,
+		"/static/a/b":     `{"route":"catchall","v":"a/b"}`,
+		"/statistics":     `{"route":"stats","v":""}`,
+		"/search":         `{"route":"search","v":""}`,
+		"/users/new":      `{"route":"new","v":""}`,
+		"/users/newer":    `{"route":"param","v":"newer"}`,
+		"/users/new/edit": `{"route":"edit","v":"new"}`,
+	}
+	for path, want := range cases {
+		req := httptest.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		r.Handler().ServeHTTP(w, req)
+
+		if got := strings.TrimSpace(w.Body.String()); got != want {
+			t.Errorf("%s: expected %s, got %d %s", path, want, w.Code, got)
+		}
+	}
+}
+
+func TestRouterRegistrationConflicts(t *testing.T) {
+	h := func(ctx *router.Context) {}
+
+	panicMessage := func(fn func()) (msg string) {
+		defer func() {
+			if v := recover(); v != nil {
+				msg = fmt.Sprint(v)
+			}
+		}()
+		fn()
+		return ""
+	}
+
+	msg := panicMessage(func() {
+		r := router.New()
+		r.GET("/users/:id", h)
+		r.GET("/users/:id", h)
+	})
+	if !strings.Contains(msg, "duplicate route GET /users/:id") || strings.Count(msg, "router_test.go:") != 2 {
+		t.Errorf("Expected duplicate route panic naming both locations, got %q", msg)
+	}
+
+	msg = panicMessage(func() {
+		r := router.New()
+		r.GET("/users/:id", h)
+		r.POST("/users/:name", h)
+	})
+	if !strings.Contains(msg, `":name"`) || !strings.Contains(msg, `":id"`) || strings.Count(msg, "router_test.go:") != 2 {
+		t.Errorf("Expected param conflict panic naming both locations, got %q", msg)
+	}
+
+	// Different constraints at the same position are allowed.
+	msg = panicMessage(func() {
+		r := router.New()
+		r.GET("/users/:id<int>", h)
+		r.GET("/users/:name", h)
+		r.POST("/users/:id<int>", h)
+	})
+	if msg != "" {
+		t.Errorf("Expected no conflict, got %q", msg)
+	}
+}
+
+func TestRouterRedirectsStayOnHost(t *testing.T) {
+	root := router.New()
+	root.GET("/", func(ctx *router.Context) {})
+
+	slug := router.New()
+	slug.SetOptions(router.Options{TrailingSlash: router.TrailingSlashRedirect})
+	slug.GET("/:slug", func(ctx *router.Context) {})
+
+	cases := []struct {
+		name     string
+		r        *router.Router
+		path     string
+		location string
+	}{
+		{"clean path", root, "/%5Cevil.com/x/..", "/%5Cevil.com"},
+		{"clean path with query", root, "/%5Cevil.com/x/..?a=1", "/%5Cevil.com?a=1"},
+		{"trailing slash", slug, "/%5Cevil.com/", "/%5Cevil.com"},
+		{"escaped question mark", slug, "/a%3Fb/", "/a%3Fb"},
+	}
+	for _, tc := range cases {
+		w := httptest.NewRecorder()
+		tc.r.Handler().ServeHTTP(w, httptest.NewRequest("GET", tc.path, nil))
+
+		loc := w.Header().Get("Location")
+		if strings.HasPrefix(loc, "//") || strings.HasPrefix(loc, "/\\") {
+			t.Errorf("%s: redirect to another host: %q", tc.name, loc)
+		}
+		if w.Code != 301 || loc != tc.location {
+			t.Errorf("%s: expected 301 to %q, got %d to %q", tc.name, tc.location, w.Code, loc)
+		}
+	}
+}
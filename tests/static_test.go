@@ -0,0 +1,156 @@
+package tests
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/alejandrombjs/go-bastion-lib/pkg/router"
+)
+
+func newStaticRouter(opts router.StaticOptions) *router.Router {
+	files := fstest.MapFS{
+		"index.html":            {Data: []byte("<h1>home</h1>")},
+		"app.3f9a2c1b7d.js":     {Data: []byte("console.log(1)")},
+		"css/site.css":          {Data: []byte("body{}")},
+		"css/site.css.gz":       {Data: []byte("gzipped")},
+		"docs/readme.txt":       {Data: []byte("docs")},
+		"docs/nested/guide.txt": {Data: []byte("guide")},
+	}
+	r := router.New()
+	r.Static("/static", files, opts)
+	return r
+}
+
+func TestStaticServesFiles(t *testing.T) {
+	r := newStaticRouter(router.StaticOptions{})
+
+	cases := []struct {
+		path   string
+		status int
+		body   string
+		cache  string
+	}{
+		{"/static/css/site.css", 200, "body{}", "no-cache"},
+		{"/static/", 200, "<h1>home</h1>", "no-cache"},
+		{"/static/app.3f9a2c1b7d.js", 200, "console.log(1)", "public, max-age=31536000, immutable"},
+		{"/static/..%2F..%2Fcss/site.css", 200, "body{}", "no-cache"},
+		{"/static/missing.txt", 404, "", ""},
+		{"/static/docs", 404, "", ""},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest("GET", tc.path, nil)
+		w := httptest.NewRecorder()
+		r.Handler().ServeHTTP(w, req)
+
+		if w.Code != tc.status {
+			t.Errorf("%s: expected status %d, got %d", tc.path, tc.status, w.Code)
+			continue
+		}
+		if tc.status != 200 {
+			continue
+		}
+		if w.Body.String() != tc.body {
+			t.Errorf("%s: expected body %q, got %q", tc.path, tc.body, w.Body.String())
+		}
+		if got := w.Header().Get("Cache-Control"); got != tc.cache {
+			t.Errorf("%s: expected Cache-Control %q, got %q", tc.path, tc.cache, got)
+		}
+	}
+}
+
+func TestStaticConditionalAndGzip(t *testing.T) {
+	r := newStaticRouter(router.StaticOptions{})
+
+	req := httptest.NewRequest("GET", "/static/css/site.css", nil)
+	req.Header.Set("Accept-Encoding", "gzip, deflate")
+	w := httptest.NewRecorder()
+	r.Handler().ServeHTTP(w, req)
+
+	if w.Body.String() != "gzipped" || w.Header().Get("Content-Encoding") != "gzip" {
+		t.Errorf("Expected precompressed sibling, got encoding %q body %q", w.Header().Get("Content-Encoding"), w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "text/css; charset=utf-8" {
+		t.Errorf("Expected css content type, got %q", ct)
+	}
+
+	req = httptest.NewRequest("GET", "/static/docs/readme.txt", nil)
+	w = httptest.NewRecorder()
+	r.Handler().ServeHTTP(w, req)
+	etag := w.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("Expected ETag header")
+	}
+
+	req = httptest.NewRequest("GET", "/static/docs/readme.txt", nil)
+	req.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	r.Handler().ServeHTTP(w, req)
+	if w.Code != 304 {
+		t.Errorf("Expected 304 for matching ETag, got %d", w.Code)
+	}
+}
+
+func TestStaticBrowseAndSPA(t *testing.T) {
+	r := newStaticRouter(router.StaticOptions{Browse: true, SPA: true})
+
+	req := httptest.NewRequest("GET", "/static/docs/", nil)
+	w := httptest.NewRecorder()
+	r.Handler().ServeHTTP(w, req)
+	if w.Code != 200 || !containsAll(w.Body.String(), `href="/static/docs/readme.txt"`, `href="/static/docs/nested/"`) {
+		t.Errorf("Expected directory listing, got %d %q", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest("GET", "/static/app/settings/profile", nil)
+	w = httptest.NewRecorder()
+	r.Handler().ServeHTTP(w, req)
+	if w.Code != 200 || w.Body.String() != "<h1>home</h1>" {
+		t.Errorf("Expected SPA fallback to index.html, got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func containsAll(s string, subs ...string) bool {
+	for _, sub := range subs {
+		if !strings.Contains(s, sub) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestStaticETagFollowsFileChanges(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(file, []byte("v1"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r := router.New()
+	r.Static("/static", os.DirFS(dir), router.StaticOptions{})
+
+	w := httptest.NewRecorder()
+	r.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/static/notes.txt", nil))
+	oldETag := w.Header().Get("ETag")
+
+	if err := os.WriteFile(file, []byte("v2 edited"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(file, later, later); err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest("GET", "/static/notes.txt", nil)
+	req.Header.Set("If-None-Match", oldETag)
+	w = httptest.NewRecorder()
+	r.Handler().ServeHTTP(w, req)
+	if w.Code != 200 || w.Body.String() != "v2 edited" {
+		t.Fatalf("Expected 200 with the new contents, got %d %q", w.Code, w.Body.String())
+	}
+	if etag := w.Header().Get("ETag"); etag == "" || etag == oldETag {
+		t.Errorf("Expected a new ETag, got %q (was %q)", etag, oldETag)
+	}
+}
@@ -0,0 +1,86 @@
+package tests
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/alejandrombjs/go-bastion-lib/pkg/router"
+)
+
+type typedCreateUser struct {
+	OrgID int64  `path:"org"`
+	Name  string `json:"name" validate:"required"`
+}
+
+type typedUser struct {
+	ID    int64  `json:"id"`
+	OrgID int64  `json:"org_id"`
+	Name  string `json:"name"`
+}
+
+type typedAccepted struct {
+	Job string `json:"job"`
+}
+
+func (typedAccepted) StatusCode() int { return http.StatusAccepted }
+
+func TestTypedHandler(t *testing.T) {
+	r := router.New()
+	r.HandleTyped(http.MethodPost, "/orgs/:org/users", router.Typed(func(ctx *router.Context, in typedCreateUser) (typedUser, error) {
+		if in.Name == "taken" {
+			return typedUser{}, router.NewHTTPError(http.StatusConflict, "name_taken", "Name already taken")
+		}
+		return typedUser{ID: 1, OrgID: in.OrgID, Name: in.Name}, nil
+	}))
+	r.HandleTyped(http.MethodGet, "/users/:id", router.Typed(func(ctx *router.Context, in *struct {
+		ID int64 `path:"id"`
+	}) (*typedUser, error) {
+		return &typedUser{ID: in.ID, Name: "Ada"}, nil
+	}))
+	r.HandleTyped(http.MethodDelete, "/users/:id", router.Typed(func(ctx *router.Context, in struct{}) (struct{}, error) {
+		return struct{}{}, nil
+	}))
+	r.HandleTyped(http.MethodPost, "/jobs", router.Typed(func(ctx *router.Context, in struct{}) (typedAccepted, error) {
+		return typedAccepted{Job: "j1"}, nil
+	}))
+	r.HandleTyped(http.MethodGet, "/fail", router.Typed(func(ctx *router.Context, in struct{}) (string, error) {
+		return "", errors.New("boom")
+	}))
+
+	cases := []struct {
+		method, path, body string
+		status             int
+		response           string
+	}{
+		{"POST", "/orgs/7/users", `{"name":"Ada"}`, 201, `{"id":1,"org_id":7,"name":"Ada"}`},
+		{"POST", "/orgs/7/users", `{"name":""}`, 422, `"code":"validation_failed"`},
+		{"POST", "/orgs/x/users", `{"name":"Ada"}`, 422, `"field":"org"`},
+		{"POST", "/orgs/7/users", `{"name":"taken"}`, 409, `"code":"name_taken"`},
+		{"GET", "/users/5", "", 200, `{"id":5,"org_id":0,"name":"Ada"}`},
+		{"DELETE", "/users/5", "", 204, ""},
+		{"POST", "/jobs", "", 202, `{"job":"j1"}`},
+		{"GET", "/fail", "", 500, `"code":"internal_error"`},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.Handler().ServeHTTP(w, req)
+
+		if w.Code != tc.status || !strings.Contains(w.Body.String(), tc.response) {
+			t.Errorf("%s %s: expected %d %s, got %d %s", tc.method, tc.path, tc.status, tc.response, w.Code, w.Body.String())
+		}
+	}
+
+	for _, info := range r.Routes() {
+		if info.Method == "POST" && info.Path == "/orgs/:org/users" {
+			if info.Request != reflect.TypeOf(typedCreateUser{}) || info.Response != reflect.TypeOf(typedUser{}) {
+				t.Errorf("Unexpected route types: %v %v", info.Request, info.Response)
+			}
+		}
+	}
+}
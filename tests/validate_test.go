@@ -0,0 +1,152 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/alejandrombjs/go-bastion-lib/pkg/response"
+	"github.com/alejandrombjs/go-bastion-lib/pkg/router"
+	"github.com/alejandrombjs/go-bastion-lib/pkg/validate"
+)
+
+type orderItem struct {
+	SKU      string `json:"sku" validate:"required,alphanum"`
+	Quantity int    `json:"quantity" validate:"min=1,even"`
+}
+
+type createOrder struct {
+	Email    string      `json:"email" validate:"required,email"`
+	Status   string      `json:"status" validate:"omitempty,oneof=draft placed"`
+	Items    []orderItem `json:"items" validate:"required,max=3"`
+	Coupon   *string     `json:"coupon" validate:"len=8"`
+	Priority int         `query:"priority" validate:"max=5"`
+}
+
+func init() {
+	validate.Register("even", func(v reflect.Value, _ string) bool {
+		return v.Int()%2 == 0
+	}, "%s must be even")
+}
+
+func TestValidateStruct(t *testing.T) {
+	valid := createOrder{Email: "ada@example.com", Items: []orderItem{{SKU: "A1", Quantity: 2}}}
+	if err := validate.Struct(&valid); err != nil {
+		t.Fatalf("Expected valid struct, got %v", err)
+	}
+
+	invalid := createOrder{
+		Email:  "not-an-email",
+		Status: "shipped",
+		Items:  []orderItem{{SKU: "A1", Quantity: 2}, {SKU: "", Quantity: 3}},
+	}
+	var errs validate.Errors
+	if !errors.As(validate.Struct(invalid), &errs) {
+		t.Fatalf("Expected validate.Errors")
+	}
+
+	var got []string
+	for _, fe := range errs {
+		got = append(got, fe.Field+":"+fe.Code)
+	}
+	want := "email:email,status:oneof,items[1].sku:required,items[1].quantity:even"
+	if strings.Join(got, ",") != want {
+		t.Errorf("Expected %s, got %s", want, strings.Join(got, ","))
+	}
+}
+
+func TestValidateRegisterDuplicatePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected panic when registering an existing rule")
+		}
+	}()
+	validate.Register("email", func(reflect.Value, string) bool { return true }, "%s")
+}
+
+type misspeltItem struct {
+	SKU string `json:"sku" validate:"requird"`
+}
+
+type misspeltRule struct {
+	Items []misspeltItem `json:"items"`
+}
+
+func TestValidateUnknownRule(t *testing.T) {
+	err := validate.Check(reflect.TypeOf(&misspeltRule{}))
+	if err == nil || !strings.Contains(err.Error(), `unknown rule "requird"`) {
+		t.Errorf("Check: expected unknown rule error, got %v", err)
+	}
+
+	err = validate.Struct(misspeltRule{Items: []misspeltItem{{}}})
+	var errs validate.Errors
+	if err == nil || errors.As(err, &errs) {
+		t.Errorf("Struct: expected a plain error, got %#v", err)
+	}
+
+	defer func() {
+		if msg, _ := recover().(string); !strings.Contains(msg, `unknown rule "requird"`) {
+			t.Errorf("Typed: expected panic naming the rule, got %q", msg)
+		}
+	}()
+	router.Typed(func(ctx *router.Context, in misspeltRule) (struct{}, error) {
+		return struct{}{}, nil
+	})
+}
+
+func TestBindValidationResponse(t *testing.T) {
+	r := router.New()
+	r.POST("/orders", func(ctx *router.Context) {
+		var req createOrder
+		if err := ctx.Bind(&req); err != nil {
+			response.ValidationError(ctx, err)
+			return
+		}
+		ctx.Status(http.StatusCreated)
+	})
+
+	cases := []struct {
+		url, body string
+		status    int
+		details   string
+	}{
+		{"/orders", `{"email":"ada@example.com","items":[{"sku":"A1","quantity":2}]}`, 201, ""},
+		{"/orders?priority=9", `{"email":"","items":[]}`, 422, "email:required,items:required,priority:max"},
+		{"/orders?priority=high", `{"email":"ada@example.com","items":[{"sku":"A1","quantity":2}]}`, 422, "priority:invalid"},
+		{"/orders", `{"email":`, 400, ""},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest("POST", tc.url, strings.NewReader(tc.body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.Handler().ServeHTTP(w, req)
+
+		if w.Code != tc.status {
+			t.Errorf("%s %s: expected %d, got %d: %s", tc.url, tc.body, tc.status, w.Code, w.Body.String())
+			continue
+		}
+		if tc.details == "" {
+			continue
+		}
+		var resp struct {
+			Error struct {
+				Code    string                `json:"code"`
+				Details []validate.FieldError `json:"details"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("Invalid JSON: %v", err)
+		}
+		var got []string
+		for _, fe := range resp.Error.Details {
+			got = append(got, fe.Field+":"+fe.Code)
+		}
+		if resp.Error.Code != "validation_failed" || strings.Join(got, ",") != tc.details {
+			t.Errorf("%s: expected %s, got %s %v", tc.url, tc.details, resp.Error.Code, got)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alejandrombjs/go-bastion-lib/pkg/router"
+)
+
+func newVersionedRouter(cfg router.VersionConfig) *router.Router {
+	r := router.New()
+	api := r.Versions("/api", cfg)
+
+	api.Version("1").GET("/users/:id", func(ctx *router.Context) {
+		ctx.JSON(200, map[string]string{"version": "1", "id": ctx.Param("id")})
+	})
+	api.Version("2").GET("/users/:id", func(ctx *router.Context) {
+		v, _ := ctx.GetString("apiVersion")
+		ctx.JSON(200, map[string]string{"version": v, "id": ctx.Param("id")})
+	})
+	api.Deprecate("1", router.Deprecation{
+		Sunset: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+		Link:   "https://example.com/migrate",
+	})
+	return r
+}
+
+func TestVersionByMediaType(t *testing.T) {
+	r := newVersionedRouter(router.VersionConfig{Strategy: router.VersionByMediaType, Vendor: "acme"})
+
+	cases := []struct {
+		accept string
+		status int
+		body   string
+	}{
+		{"application/vnd.acme.v2+json", 200, `{"id":"7","version":"2"}`},
+		{"application/json; version=2", 200, `{"id":"7","version":"2"}`},
+		{"application/json", 200, `{"id":"7","version":"1"}`},
+		{"application/vnd.acme.v3+json", 406, `{"error":"Not Acceptable"}`},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest("GET", "/api/users/7", nil)
+		req.Header.Set("Accept", tc.accept)
+		w := httptest.NewRecorder()
+		r.Handler().ServeHTTP(w, req)
+
+		if got := strings.TrimSpace(w.Body.String()); w.Code != tc.status || got != tc.body {
+			t.Errorf("Accept %q: expected %d %s, got %d %s", tc.accept, tc.status, tc.body, w.Code, got)
+		}
+		if w.Header().Get("Vary") != "Accept" {
+			t.Errorf("Accept %q: expected Vary: Accept, got %q", tc.accept, w.Header().Get("Vary"))
+		}
+	}
+}
+
+func TestVersionByHeaderDeprecation(t *testing.T) {
+	r := newVersionedRouter(router.VersionConfig{Strategy: router.VersionByHeader, Default: "2"})
+
+	req := httptest.NewRequest("GET", "/api/users/7", nil)
+	req.Header.Set("X-API-Version", "1")
+	w := httptest.NewRecorder()
+	r.Handler().ServeHTTP(w, req)
+
+	if got := strings.TrimSpace(w.Body.String()); got != `{"id":"7","version":"1"}` {
+		t.Errorf("Expected version 1, got %s", got)
+	}
+	if w.Header().Get("Deprecation") != "true" {
+		t.Errorf("Expected Deprecation header, got %q", w.Header().Get("Deprecation"))
+	}
+	if w.Header().Get("Sunset") != "Tue, 01 Jan 2030 00:00:00 GMT" {
+		t.Errorf("Expected Sunset header, got %q", w.Header().Get("Sunset"))
+	}
+	if !strings.Contains(w.Header().Get("Link"), `<https://example.com/migrate>`) {
+		t.Errorf("Expected Link header, got %q", w.Header().Get("Link"))
+	}
+
+	req = httptest.NewRequest("GET", "/api/users/7", nil)
+	w = httptest.NewRecorder()
+	r.Handler().ServeHTTP(w, req)
+	if got := strings.TrimSpace(w.Body.String()); got != `{"id":"7","version":"2"}` || w.Header().Get("Deprecation") != "" {
+		t.Errorf("Expected default version 2 without deprecation, got %s", got)
+	}
+}
+
+func TestVersionByPath(t *testing.T) {
+	r := newVersionedRouter(router.VersionConfig{Strategy: router.VersionByPath})
+
+	cases := map[string]int{
+		"/api/v2/users/7": 200,
+		"/api/v1/users/7": 200,
+		"/api/users/7":    200,
+		"/api/v9/users/7": 406,
+		"/api/v2/nope":    404,
+	}
+	for path, status := range cases {
+		req := httptest.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		r.Handler().ServeHTTP(w, req)
+		if w.Code != status {
+			t.Errorf("%s: expected %d, got %d", path, status, w.Code)
+		}
+	}
+
+	var paths []string
+	for _, rt := range r.Routes() {
+		if strings.Contains(rt.Path, "*") {
+			t.Errorf("Version dispatcher listed: %s %s", rt.Method, rt.Path)
+		}
+		if rt.Version != "" {
+			paths = append(paths, rt.Version+" "+rt.Path)
+		}
+	}
+	if got := strings.Join(paths, ","); got != "1 /api/v1/users/:id,2 /api/v2/users/:id" {
+		t.Errorf("Unexpected versioned routes: %s", got)
+	}
+}
+
+// Typed handlers negotiate their response against the same Accept header
+// that selects the version, so vendor +json types must render as JSON.
+func TestVersionByMediaTypeTyped(t *testing.T) {
+	r := router.New()
+	api := r.Versions("/api", router.VersionConfig{Strategy: router.VersionByMediaType, Vendor: "acme"})
+	api.Version("2").HandleTyped(http.MethodGet, "/users/:id", router.Typed(func(ctx *router.Context, in struct {
+		ID string `path:"id"`
+	}) (map[string]string, error) {
+		return map[string]string{"id": in.ID}, nil
+	}))
+
+	for _, accept := range []string{"application/vnd.acme.v2+json", "application/vnd.acme+json; version=2"} {
+		req := httptest.NewRequest("GET", "/api/users/7", nil)
+		req.Header.Set("Accept", accept)
+		w := httptest.NewRecorder()
+		r.Handler().ServeHTTP(w, req)
+
+		if got := strings.TrimSpace(w.Body.String()); w.Code != 200 || got != `{"id":"7"}` {
+			t.Errorf("Accept %q: expected 200 {\"id\":\"7\"}, got %d %s", accept, w.Code, got)
+		}
+		if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Accept %q: expected Content-Type application/json, got %q", accept, ct)
+		}
+	}
+}